@@ -0,0 +1,133 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/mod"
+	"github.com/bwplotka/bingo/pkg/osv"
+	"github.com/efficientgo/core/errcapture"
+	"github.com/efficientgo/core/errors"
+	"golang.org/x/mod/module"
+)
+
+// auditFinding is a vulnerability affecting the module the pinned tool version is built with.
+type auditFinding struct {
+	Tool    string
+	Version string
+	Module  module.Version
+	osv.Finding
+}
+
+// toolModules returns modules the tool pinned in the given module file is built with: the tool module, its indirect
+// dependencies and the modules downloaded for the build according to the sum file, with replace directives applied.
+// Modules replaced with local directories are skipped, as they have no version.
+func toolModules(modFile string) (_ []module.Version, err error) {
+	pkg, err := bingo.ModDirectPackage(modFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read direct package from %v", modFile)
+	}
+	indirect, err := bingo.ModIndirectModules(modFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read indirect modules from %v", modFile)
+	}
+	sum, err := mod.ReadSumFile(mod.SumFilePath(modFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "read sum file")
+	}
+
+	mf, err := mod.OpenFileForRead(modFile)
+	if err != nil {
+		return nil, err
+	}
+	defer errcapture.Do(&err, mf.Close, "close")
+	replaces := mf.ReplaceDirectives()
+
+	var (
+		mods = append([]module.Version{pkg.Module}, indirect...)
+		seen = map[string]struct{}{}
+	)
+	for _, m := range mods {
+		seen[m.Path] = struct{}{}
+	}
+	for _, m := range sum {
+		// Required modules are selected by Go, regardless of other versions recorded in the sum file.
+		if _, ok := seen[m.Path]; !ok {
+			mods = append(mods, m)
+		}
+	}
+
+	ret := make([]module.Version, 0, len(mods))
+ModLoop:
+	for _, m := range mods {
+		for _, r := range replaces {
+			if r.Old.Path != m.Path || (r.Old.Version != "" && r.Old.Version != m.Version) {
+				continue
+			}
+			if r.New.Version == "" {
+				continue ModLoop
+			}
+			m = r.New
+			break
+		}
+		ret = append(ret, m)
+	}
+	return ret, nil
+}
+
+// auditTools returns vulnerabilities from the given database affecting modules of all versions of the given pinned tools.
+func auditTools(db *osv.DB, modDir string, pkgs []bingo.PackageRenderable) ([]auditFinding, error) {
+	var findings []auditFinding
+	for _, p := range pkgs {
+		for _, v := range p.Versions {
+			mods, err := toolModules(filepath.Join(modDir, v.ModFile))
+			if err != nil {
+				return nil, errors.Wrapf(err, "%v@%v", p.Name, v.Version)
+			}
+			for _, m := range mods {
+				for _, f := range db.Query(m) {
+					findings = append(findings, auditFinding{Tool: p.Name, Version: v.Version, Module: m, Finding: f})
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// failingFindings returns number of findings with severity equal to or higher than the given one. Severity of findings
+// is resolved through their aliases (see osv.Finding), so findings still not rated are counted only for the lowest
+// severity, otherwise every threshold would fail on them.
+func failingFindings(findings []auditFinding, failOn osv.Severity) (n int) {
+	for _, f := range findings {
+		if f.Severity >= failOn || (f.Severity == osv.SeverityUnknown && failOn <= osv.SeverityLow) {
+			n++
+		}
+	}
+	return n
+}
+
+// printAuditFindings prints findings as a table, with the version fixing the vulnerability, if any.
+func printAuditFindings(w io.Writer, findings []auditFinding) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "TOOL\tMODULE\tVULNERABILITY\tSEVERITY\tFIXED IN\tSUMMARY")
+	for _, f := range findings {
+		id := f.ID
+		if len(f.Aliases) > 0 {
+			id += " (" + strings.Join(f.Aliases, ", ") + ")"
+		}
+		fixed := "-"
+		if f.Fixed != "" {
+			fixed = f.Fixed
+		}
+		_, _ = fmt.Fprintf(tw, "%v@%v\t%v\t%v\t%v\t%v\t%v\n", f.Tool, f.Version, f.Module, id, f.Severity, fixed, f.Summary)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/osv"
+	"github.com/efficientgo/core/testutil"
+	"golang.org/x/mod/module"
+)
+
+func TestAuditTools(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "tool.mod"), []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.25.3
+
+require example.com/tool v1.5.1 // cmd/tool
+
+require (
+	example.com/parser v1.1.9 // indirect
+	example.com/local v1.0.0 // indirect
+)
+
+replace example.com/local => ../local
+`), os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "tool.sum"), []byte(`example.com/parser v1.1.0 h1:aaaa=
+example.com/parser v1.1.9 h1:bbbb=
+example.com/tool v1.5.1 h1:cccc=
+example.com/transitive v0.1.0 h1:dddd=
+example.com/transitive v0.1.0/go.mod h1:eeee=
+`), os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "other.mod"), []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.25.3
+
+require example.com/parser v1.4.0 // cmd/parse
+
+replace example.com/parser v1.4.0 => example.com/parser v1.3.3
+`), os.ModePerm))
+
+	mods, err := toolModules(filepath.Join(modDir, "tool.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []module.Version{
+		{Path: "example.com/tool", Version: "v1.5.1"},
+		{Path: "example.com/parser", Version: "v1.1.9"},
+		{Path: "example.com/transitive", Version: "v0.1.0"},
+	}, mods)
+
+	pkgs, err := bingo.ListPinnedMainPackages(log.New(os.Stderr, "", 0), modDir, false)
+	testutil.Ok(t, err)
+	bingo.SortRenderables(pkgs)
+
+	db, err := osv.Load("pkg/osv/testdata/db")
+	testutil.Ok(t, err)
+
+	findings, err := auditTools(db, modDir, pkgs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 5, len(findings))
+	// Not rated findings can't be ruled out only for the lowest severity.
+	testutil.Equals(t, 5, failingFindings(findings, osv.SeverityLow))
+	testutil.Equals(t, 4, failingFindings(findings, osv.SeverityMedium))
+	// Go vulnerability database entries are rated through their GitHub advisory aliases.
+	testutil.Equals(t, 3, failingFindings(findings, osv.SeverityHigh))
+	testutil.Equals(t, 1, failingFindings(findings, osv.SeverityCritical))
+
+	b := bytes.Buffer{}
+	testutil.Ok(t, printAuditFindings(&b, findings))
+	testutil.Equals(t, `TOOL          MODULE                         VULNERABILITY                                      SEVERITY  FIXED IN  SUMMARY
+other@v1.4.0  example.com/parser@v1.3.3      GO-2099-0001 (CVE-2099-0001)                       high      v1.3.4    Infinite loop when parsing malformed input in example.com/parser
+tool@v1.5.1   example.com/tool@v1.5.1        GHSA-aaaa-bbbb-cccc (CVE-2099-0003)                critical  v1.9.1    Remote code execution in example.com/tool
+tool@v1.5.1   example.com/tool@v1.5.1        GHSA-dddd-eeee-ffff                                medium    -         Denial of service in example.com/tool
+tool@v1.5.1   example.com/parser@v1.1.9      GHSA-gggg-hhhh-iiii (CVE-2099-0001, GO-2099-0001)  high      v1.2.0    example.com/parser vulnerable to infinite loop
+tool@v1.5.1   example.com/transitive@v0.1.0  GO-2099-0003                                       unknown   -         Path traversal in example.com/transitive
+`, b.String())
+}
@@ -11,12 +11,16 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/makefile"
+	"github.com/bwplotka/bingo/pkg/osv"
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/bwplotka/bingo/pkg/version"
 )
@@ -28,6 +32,8 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 		name     string
 		insecure bool
 		link     bool
+		shims    bool
+		track    bool
 		timeOut  uint
 	)
 
@@ -37,6 +43,8 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 			"bingo get github.com/fatih/faillint@latest\n" +
 			"bingo get github.com/fatih/faillint@v1.5.0\n" +
 			"bingo get github.com/fatih/faillint@v1.1.0,v1.5.0\n" +
+			"bingo get --track github.com/fatih/faillint@main\n" +
+			"bingo get --shims\n" +
 			"bingo get github.com/fatih/faillint@none // this will be deleted ",
 		Short: "add development tools to the current project (e.g: bingo get github.com/fatih/faillint@latest)",
 		Long: "go get like, simple CLI that allows automated versioning of Go package level \n" +
@@ -48,6 +56,12 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 			if len(args) > 1 {
 				return errors.New("too many arguments except none or binary/package")
 			}
+			if track && len(args) == 0 {
+				return errors.New("--track requires package or binary with Git branch or tag name after @")
+			}
+			if shims && link {
+				return errors.New("--shims and -l cannot be used together; shims replace the global <tool> soft link")
+			}
 			if len(rename) > 0 && len(name) > 0 {
 				return errors.New("Both -n and -r were specified. You can either rename or create new one.")
 			}
@@ -76,6 +90,11 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 				}
 			}()
 
+			bingoCfg, err := bingo.LoadConfig(modDirAbs)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+
 			r, err := runner.NewRunner(ctx, logger, insecure, goCmd)
 			if err != nil {
 				return err
@@ -83,14 +102,17 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 			if verbose {
 				r.Verbose()
 			}
+			r.SetGoEnv(bingoCfg.GoEnvSlice())
 
 			cfg := getConfig{
 				runner:    r,
 				modDir:    modDirAbs,
 				relModDir: moddir,
+				bingoCfg:  bingoCfg,
 				name:      name,
 				rename:    rename,
 				link:      link,
+				track:     track,
 				timeOut:   timeOut,
 				verbose:   verbose,
 			}
@@ -102,15 +124,13 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 			if err := get(ctx, logger, cfg, target); err != nil {
 				return errors.Wrap(err, "get")
 			}
-
-			pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, true)
-			if err != nil {
-				return errors.Wrap(err, "list pinned")
-			}
-			if len(pkgs) == 0 {
-				return bingo.RemoveHelpers(modDirAbs)
+			if shims {
+				if err := os.MkdirAll(filepath.Join(modDirAbs, bingo.ShimsDir), os.ModePerm); err != nil {
+					return errors.Wrap(err, "create shims dir")
+				}
 			}
-			return bingo.GenHelpers(moddir, version.Version, pkgs)
+
+			return regenHelpers(logger, modDirAbs)
 		},
 	}
 	flags := cmd.Flags()
@@ -120,14 +140,481 @@ func NewBingoGetCommand(logger *log.Logger) *cobra.Command {
 	flags.StringVarP(&rename, "rename", "r", "", "The -r flag instructs to get existing binary and rename it with given name. Allowed characters [A-z0-9._-]. \n"+
 		"If -r is used and no package/binary is specified or non existing binary name is used, bingo will return error. Cannot be used with -n.")
 	flags.StringVar(&goCmd, "go", "go", "Path to the go command.")
-	flags.BoolVar(&insecure, "insecure", insecure, `Use -insecure flag when using 'go get'`)
+	flags.BoolVar(&insecure, "insecure", insecure, "Allow insecure schemes and skip checksum database for all modules. Deprecated: configure GOINSECURE in "+bingo.ConfigFileName+" instead.")
 	flags.BoolVarP(&link, "link", "l", link, "If enabled, bingo will also create soft link called <tool> that links to the current <tool>-<version> binary.\n"+
 		"Use Variables.mk and variables.env if you want to be sure that what you are invoking is what is pinned.")
+	flags.BoolVar(&shims, "shims", shims, "If enabled, bingo will also generate <tool> shims in "+bingo.ShimsDir+" directory inside the mod directory, which run\n"+
+		"the version pinned in the nearest <tool>.mod, building it if needed. Once enabled, shims are maintained by following commands.\n"+
+		"Add that directory to your PATH instead of using -l, which mutates GOBIN shared with other projects.")
+	flags.BoolVar(&track, "track", track, "If enabled, the version after @ is treated as Git branch or tag name to follow. bingo pins its current head\n"+
+		"(pseudo-version) and records the branch in the module file, so each following 'bingo get' re-resolves it to the latest head.")
 	flags.UintVarP(&timeOut, "timeout", "t", 5, "The maximum time (in minutes) to wait for each go command before killing it.\n"+
 		"Set this flag to 0 to indefinitely wait on them.")
 	return cmd
 }
 
+func NewBingoRmCommand(logger *log.Logger) *cobra.Command {
+	var (
+		goCmd string
+		purge bool
+	)
+
+	cmd := &cobra.Command{
+		Use: "rm [flags] <binary>...",
+		Example: "bingo rm faillint\n" +
+			"bingo rm --purge faillint goimports",
+		Short: "remove development tools from the current project (e.g: bingo rm faillint)",
+		Long: "Remove unpins given tools from the current project by removing their module files and regenerating helpers.\n" +
+			"Equivalent of 'bingo get <binary>@none', but with the ability to also remove built binaries.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("at least one binary name is required")
+			}
+			if len(goCmd) == 0 {
+				return errors.New("'go' flag cannot be empty")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+
+			var gobinDir, manifestFile string
+			if purge {
+				r, err := runner.NewRunner(ctx, logger, false, goCmd)
+				if err != nil {
+					return err
+				}
+				gobinDir, err = gobin(r.With(ctx, "", modDirAbs, nil))
+				if err != nil {
+					return errors.Wrap(err, "deduct GOBIN")
+				}
+				manifestFile = defaultManifestFile(logger)
+			}
+
+			if err := remove(logger, modDirAbs, gobinDir, manifestFile, verbose, args...); err != nil {
+				return errors.Wrap(err, "rm")
+			}
+			return regenHelpers(logger, modDirAbs)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&goCmd, "go", "go", "Path to the go command.")
+	flags.BoolVar(&purge, "purge", purge, "If enabled, bingo will also remove all <tool>-<version> binaries of the removed tools from GOBIN\n"+
+		"together with the <tool> soft link created by 'bingo get -l', if it points to one of them. Note that the same binaries might be used by other projects.")
+	return cmd
+}
+
+func NewBingoMvCommand(logger *log.Logger) *cobra.Command {
+	var goCmd string
+
+	cmd := &cobra.Command{
+		Use:     "mv [flags] <binary> <new name>",
+		Example: "bingo mv faillint my-faillint",
+		Short:   "rename development tool in the current project (e.g: bingo mv faillint my-faillint)",
+		Long: "Mv renames pinned tool together with already built <tool>-<version> binaries in GOBIN, so nothing has to be rebuilt.\n" +
+			"Contrary to 'bingo get -r', the old binaries are not kept.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("exactly two arguments are required: existing binary name and new name")
+			}
+			if len(goCmd) == 0 {
+				return errors.New("'go' flag cannot be empty")
+			}
+			if !regexp.MustCompile(`[a-zA-Z0-9.-_]+`).MatchString(args[1]) {
+				return errors.New("new name contains not allowed characters")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+
+			r, err := runner.NewRunner(ctx, logger, false, goCmd)
+			if err != nil {
+				return err
+			}
+			gobinDir, err := gobin(r.With(ctx, "", modDirAbs, nil))
+			if err != nil {
+				return errors.Wrap(err, "deduct GOBIN")
+			}
+
+			if err := move(logger, modDirAbs, gobinDir, defaultManifestFile(logger), verbose, args[0], args[1]); err != nil {
+				return errors.Wrap(err, "mv")
+			}
+			return regenHelpers(logger, modDirAbs)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&goCmd, "go", "go", "Path to the go command.")
+	return cmd
+}
+
+func NewBingoGcCommand(logger *log.Logger) *cobra.Command {
+	var (
+		goCmd        string
+		manifestFile string
+		dryRun       bool
+		keepLast     int
+		olderThan    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use: "gc [flags] [<project directory>...]",
+		Example: "bingo gc\n" +
+			"bingo gc --dry-run ~/repos/thanos ~/repos/prometheus\n" +
+			"bingo gc --keep-last 2 --older-than 720h",
+		Short: "remove versioned binaries from GOBIN that are not pinned by any of the given projects",
+		Long: "Gc scans bingo module directories (see -m flag) of given projects (current directory by default) and removes\n" +
+			"<tool>-<version> binaries from GOBIN that none of those projects pin. Only binaries built by bingo are considered.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(goCmd) == 0 {
+				return errors.New("'go' flag cannot be empty")
+			}
+			if keepLast < 0 {
+				return errors.New("--keep-last cannot be negative")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			modDirs := make([]string, 0, len(args))
+			for _, a := range args {
+				d, err := filepath.Abs(projectModDir(a, moddir))
+				if err != nil {
+					return errors.Wrap(err, "abs")
+				}
+				modDirs = append(modDirs, d)
+			}
+			pinned, err := pinnedBinarySet(logger, modDirs...)
+			if err != nil {
+				return err
+			}
+
+			r, err := runner.NewRunner(ctx, logger, false, goCmd)
+			if err != nil {
+				return err
+			}
+			gobinDir, err := gobin(r.With(ctx, "", "", nil))
+			if err != nil {
+				return errors.Wrap(err, "deduct GOBIN")
+			}
+
+			if manifestFile == "" {
+				manifestFile, err = bingo.DefaultManifestFile()
+				if err != nil {
+					return errors.Wrap(err, "manifest file")
+				}
+			}
+
+			removed, err := gc(logger, gcConfig{
+				gobinDir:     gobinDir,
+				manifestFile: manifestFile,
+				pinned:       pinned,
+				dryRun:       dryRun,
+				keepLast:     keepLast,
+				minAge:       olderThan,
+				now:          time.Now(),
+				verbose:      verbose,
+			})
+			if err != nil {
+				return errors.Wrap(err, "gc")
+			}
+			for _, p := range removed {
+				if dryRun {
+					_, _ = fmt.Fprintln(os.Stdout, "would remove", p)
+					continue
+				}
+				_, _ = fmt.Fprintln(os.Stdout, "removed", p)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&goCmd, "go", "go", "Path to the go command.")
+	flags.StringVar(&manifestFile, "manifest", "", "Path to the manifest file where bingo records all built binaries. Defaults to <user cache dir>/bingo/binaries.jsonl.")
+	flags.BoolVar(&dryRun, "dry-run", dryRun, "If enabled, bingo only prints binaries that would be removed.")
+	flags.IntVar(&keepLast, "keep-last", 0, "Number of most recently built, unpinned binaries to keep for each tool.")
+	flags.DurationVar(&olderThan, "older-than", 0, "Remove only binaries built earlier than given duration ago (e.g. 720h).")
+	return cmd
+}
+
+// defaultManifestFile returns the manifest file of binaries built by bingo or empty string, if it can't be found.
+func defaultManifestFile(logger *log.Logger) string {
+	manifestFile, err := bingo.DefaultManifestFile()
+	if err != nil {
+		logger.Println("WARNING: cannot find manifest file; it will not be updated:", err)
+		return ""
+	}
+	return manifestFile
+}
+
+// regenHelpers regenerates helpers for all pinned tools or removes them if nothing is pinned.
+func regenHelpers(logger *log.Logger, modDirAbs string) error {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, true)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	cfg, err := bingo.LoadConfig(modDirAbs)
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+	// Env files referenced by tools pinned since the mod directory was created have to be committed too.
+	if err := writeGitignore(modDirAbs, cfg, pkgs); err != nil {
+		return errors.Wrap(err, "write gitignore")
+	}
+	shims, err := bingo.ShimsEnabled(modDirAbs)
+	if err != nil {
+		return errors.Wrap(err, "check shims")
+	}
+	if shims {
+		if err := bingo.GenShims(moddir, version.Version, cfg, pkgs); err != nil {
+			return errors.Wrap(err, "generate shims")
+		}
+	}
+	if len(pkgs) == 0 {
+		return bingo.RemoveHelpers(modDirAbs, cfg)
+	}
+	return bingo.GenHelpers(moddir, version.Version, cfg, pkgs)
+}
+
+func NewBingoInitCommand(logger *log.Logger) *cobra.Command {
+	var mkFile string
+
+	cmd := &cobra.Command{
+		Use:     "init [flags]",
+		Example: "bingo init\nbingo -m tools/.bingo init --makefile=build/Makefile",
+		Short:   "create mod directory and include its Variables.mk in the project Makefile",
+		Long: "Init creates mod directory (if missing) and makes sure project Makefile includes Variables.mk helper, directly or\n" +
+			"through other included makefiles. If not, include directive is added after the last include or before the first rule.\n" +
+			"By default, first existing GNUmakefile, makefile or Makefile in the current directory is used (Makefile is created if none exists).",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			cfg, err := bingo.LoadConfig(modDirAbs)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+			if !slices.Contains(cfg.EnabledHelpers(), "mk") {
+				return errors.Errorf("mk helper is disabled in %v", filepath.Join(moddir, bingo.ConfigFileName))
+			}
+			if err := ensureModDirExists(logger, moddir, cfg); err != nil {
+				return errors.Wrap(err, "ensure mod dir")
+			}
+
+			if mkFile == "" {
+				mkFile = defaultMakefile()
+			}
+			mkFileAbs, err := filepath.Abs(mkFile)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			helper := filepath.Join(modDirAbs, filepath.FromSlash(cfg.HelperFileName("mk")))
+			include, err := filepath.Rel(filepath.Dir(mkFileAbs), helper)
+			if err != nil {
+				return errors.Wrap(err, "rel")
+			}
+
+			line, err := initMakefile(mkFile, include)
+			if err != nil {
+				return errors.Wrapf(err, "init %v", mkFile)
+			}
+			if line == 0 {
+				logger.Printf("%v already includes %v, nothing to do\n", mkFile, filepath.ToSlash(include))
+			} else {
+				logger.Printf("Added '-include %v' to %v at line %d\n", filepath.ToSlash(include), mkFile, line)
+			}
+			if _, err := os.Stat(helper); os.IsNotExist(err) {
+				logger.Printf("NOTE: %v does not exist yet; run 'bingo get <tool>' to pin the first tool and generate it\n", filepath.Join(moddir, filepath.FromSlash(cfg.HelperFileName("mk"))))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&mkFile, "makefile", "", "Path to the project makefile. Defaults to GNUmakefile, makefile or Makefile in the current directory.")
+	return cmd
+}
+
+func NewBingoLintCommand(logger *log.Logger) *cobra.Command {
+	var mkFile string
+
+	cmd := &cobra.Command{
+		Use:     "lint [flags]",
+		Example: "bingo lint\nbingo lint --makefile=build/Makefile",
+		Short:   "check how project makefiles use pinned tools (e.g. unused or not pinned tools)",
+		Long: "Lint scans the project makefile and its includes for references to pinned tools and reports tools that are pinned,\n" +
+			"but never referenced, undefined variables used as commands (likely tools that are not pinned) and recipes calling\n" +
+			"pinned tools by their bare name, bypassing the pinned version. It fails if any issue was found.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			bingo.SortRenderables(pkgs)
+
+			cfg, err := bingo.LoadConfig(modDirAbs)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+			if mkFile == "" {
+				mkFile = defaultMakefile()
+			}
+
+			issues, err := lintMakefile(mkFile, moddir, filepath.Join(moddir, filepath.FromSlash(cfg.HelperFileName("mk"))), pkgs)
+			if err != nil {
+				return err
+			}
+			for _, i := range issues {
+				_, _ = fmt.Fprintln(os.Stdout, i)
+			}
+			if len(issues) > 0 {
+				// Issues are not usage errors.
+				cmd.SilenceUsage = true
+				return errors.Errorf("found %d issue(s)", len(issues))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&mkFile, "makefile", "", "Path to the project makefile. Defaults to GNUmakefile, makefile or Makefile in the current directory.")
+	return cmd
+}
+
+func NewBingoAuditCommand(logger *log.Logger) *cobra.Command {
+	var (
+		dbPath string
+		failOn string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "audit [flags]",
+		Example: "bingo audit --db=osv/Go/all.zip\nbingo audit --db=osv/ --fail-on=high",
+		Short:   "check pinned tools and their dependencies against local vulnerability database in OSV format",
+		Long: "Audit matches modules (including indirect ones) every pinned tool version is built with, according to its module and sum\n" +
+			"files, against vulnerability database in OSV format stored on disk (e.g. https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip),\n" +
+			"so it works offline. Database can be a directory of JSON files, a zip archive of them or a single JSON file. It prints\n" +
+			"affected tools with versions fixing vulnerabilities, reporting entries aliased with each other (e.g. Go and GitHub advisories)\n" +
+			"once. With --fail-on it fails if any vulnerability is rated with the given or higher severity. Vulnerabilities not rated\n" +
+			"in their entry (e.g. from Go vulnerability database) are rated by entries they're aliased with; ones still not rated fail\n" +
+			"only --fail-on=low.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			failOnSeverity := osv.SeverityUnknown
+			if failOn != "none" {
+				s, err := osv.ParseSeverity(failOn)
+				if err != nil || s == osv.SeverityUnknown {
+					return errors.Errorf("invalid --fail-on value %q; expected none, low, medium, high or critical", failOn)
+				}
+				failOnSeverity = s
+			}
+			if dbPath == "" {
+				return errors.New("path to the vulnerability database is required; download e.g. https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip and pass it with --db")
+			}
+
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			bingo.SortRenderables(pkgs)
+
+			db, err := osv.Load(dbPath)
+			if err != nil {
+				return errors.Wrap(err, "load vulnerability database")
+			}
+			findings, err := auditTools(db, modDirAbs, pkgs)
+			if err != nil {
+				return errors.Wrap(err, "audit")
+			}
+			if len(findings) == 0 {
+				logger.Printf("no known vulnerabilities found in %d pinned tool(s), using %d vulnerabilities from %v\n", len(pkgs), db.Len(), dbPath)
+				return nil
+			}
+			if err := printAuditFindings(os.Stdout, findings); err != nil {
+				return err
+			}
+			if failOn == "none" {
+				return nil
+			}
+			if n := failingFindings(findings, failOnSeverity); n > 0 {
+				// Findings are not usage errors.
+				cmd.SilenceUsage = true
+				if failOnSeverity == osv.SeverityLow {
+					return errors.Errorf("found %d vulnerability(ies) rated %v or higher, or not rated", n, failOnSeverity)
+				}
+				return errors.Errorf("found %d vulnerability(ies) rated %v or higher", n, failOnSeverity)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dbPath, "db", "", "Path to the vulnerability database in OSV format: directory of JSON files, zip archive of them or a single JSON file.")
+	cmd.Flags().StringVar(&failOn, "fail-on", "none", "Fail if any vulnerability has the given or higher severity. One of none, low, medium, high or critical.")
+	return cmd
+}
+
+func NewBingoHelpTargetsCommand(logger *log.Logger) *cobra.Command {
+	var mkFile string
+
+	cmd := &cobra.Command{
+		Use:     "help-targets [flags]",
+		Example: "bingo help-targets\n\n# In Makefile:\n# Print this help.\nhelp:\n\t@bingo help-targets",
+		Short:   "print documented targets of the project Makefile and pinned tools",
+		Long: "Help-targets prints all targets of the project makefile and its includes, documented with comments directly\n" +
+			"above them, together with pinned tools available through Variables.mk helper and their versions.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			bingo.SortRenderables(pkgs)
+
+			cfg, err := bingo.LoadConfig(modDirAbs)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+			if mkFile == "" {
+				mkFile = defaultMakefile()
+			}
+			nodes, err := makefile.ParseFileRecursive(mkFile)
+			if err != nil {
+				return errors.Wrapf(err, "parse %v", mkFile)
+			}
+			return printTargetsHelp(os.Stdout, nodes, filepath.Join(moddir, filepath.FromSlash(cfg.HelperFileName("mk"))), pkgs)
+		},
+	}
+	cmd.Flags().StringVar(&mkFile, "makefile", "", "Path to the project makefile. Defaults to GNUmakefile, makefile or Makefile in the current directory.")
+	return cmd
+}
+
 func NewBingoListCommand(logger *log.Logger) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list <flags> [<package or binary>]",
@@ -160,6 +647,106 @@ func NewBingoListCommand(logger *log.Logger) *cobra.Command {
 	return cmd
 }
 
+func NewBingoHashCommand(logger *log.Logger) *cobra.Command {
+	var goCmd string
+
+	cmd := &cobra.Command{
+		Use:     "hash [flags]",
+		Example: "bingo hash",
+		Short:   "print digest of all pinned tools and Go version (e.g. for CI cache keys)",
+		Long: "Hash prints deterministic digest of all module and sum files in the mod directory, bingo.yaml, env files referenced\n" +
+			"by module files and version of the go command,\n" +
+			"so CI can key its GOBIN or GOMODCACHE caches on the set of pinned tools. Temporary and generated files are ignored.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDirAbs); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", moddir)
+			}
+
+			r, err := runner.NewRunner(cmd.Context(), logger, false, goCmd)
+			if err != nil {
+				return err
+			}
+			h, err := bingo.HashModDir(modDirAbs, "go"+r.GoVersion().String())
+			if err != nil {
+				return errors.Wrap(err, "hash")
+			}
+			_, err = fmt.Fprintln(os.Stdout, h)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&goCmd, "go", "go", "Path to the go command.")
+	return cmd
+}
+
+func NewBingoExportCommand(logger *log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export pinned tools to other formats (e.g: bingo export dockerfile)",
+		Long:  "Export renders pinned tools in formats used by other tools, so versions stay single-sourced in the mod directory.",
+	}
+	cmd.AddCommand(newBingoExportDockerfileCommand(logger))
+	return cmd
+}
+
+func newBingoExportDockerfileCommand(logger *log.Logger) *cobra.Command {
+	var (
+		goCmd     string
+		baseImage string
+		stage     string
+	)
+
+	cmd := &cobra.Command{
+		Use: "dockerfile [flags]",
+		Example: "bingo export dockerfile > tools.Dockerfile\n" +
+			"bingo export dockerfile --base-image=golang:1.25-alpine --stage=tools",
+		Short: "print multi-stage Dockerfile fragment installing all pinned tools",
+		Long: "Dockerfile prints multi-stage Dockerfile fragment which copies module files of each pinned tool and builds it\n" +
+			"in a separate layer, with the exact build envs and flags, so the layer cache is invalidated only if the tool changes.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modDirAbs, err := filepath.Abs(moddir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDirAbs, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			if len(pkgs) == 0 {
+				return errors.Errorf("no tools pinned in %v", moddir)
+			}
+			bingo.SortRenderables(pkgs)
+
+			cfg, err := bingo.LoadConfig(modDirAbs)
+			if err != nil {
+				return errors.Wrap(err, "load config")
+			}
+
+			if baseImage == "" {
+				r, err := runner.NewRunner(cmd.Context(), logger, false, goCmd)
+				if err != nil {
+					return err
+				}
+				baseImage = fmt.Sprintf("golang:%d.%d", r.GoVersion().Major(), r.GoVersion().Minor())
+			}
+			return bingo.ExportDockerfile(os.Stdout, moddir, version.Version, bingo.DockerfileConfig{
+				BaseImage: baseImage,
+				Stage:     stage,
+			}, cfg, pkgs)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&goCmd, "go", "go", "Path to the go command, used to detect default base image.")
+	flags.StringVar(&baseImage, "base-image", "", "Image with Go toolchain used to build tools. Defaults to golang:<major.minor version of local go command>.")
+	flags.StringVar(&stage, "stage", "bingo", "Name of the build stage with installed tools.")
+	return cmd
+}
+
 func NewBingoVersionCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
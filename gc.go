@@ -0,0 +1,150 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/errors"
+)
+
+type gcConfig struct {
+	gobinDir     string
+	manifestFile string
+	// pinned is a set of <name>-<version> binaries that are pinned by at least one scanned project.
+	pinned map[string]struct{}
+
+	dryRun   bool
+	keepLast int
+	minAge   time.Duration
+	now      time.Time
+
+	verbose bool
+}
+
+// projectModDir returns the mod directory of the given project. Absolute mod directory is used as is.
+func projectModDir(project, modDir string) string {
+	if filepath.IsAbs(modDir) {
+		return modDir
+	}
+	return filepath.Join(project, modDir)
+}
+
+// pinnedBinarySet returns set of <name>-<version> binaries pinned in all given mod directories.
+func pinnedBinarySet(logger *log.Logger, modDirs ...string) (map[string]struct{}, error) {
+	pinned := map[string]struct{}{}
+	for _, d := range modDirs {
+		if _, err := os.Stat(d); err != nil {
+			return nil, errors.Wrapf(err, "stat bingo module dir %s", d)
+		}
+
+		pkgs, err := bingo.ListPinnedMainPackages(logger, d, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list pinned in %v", d)
+		}
+		for _, p := range pkgs {
+			for _, v := range p.Versions {
+				pinned[p.Name+"-"+v.Version] = struct{}{}
+			}
+		}
+	}
+	return pinned, nil
+}
+
+// gc removes binaries from GOBIN that were built by bingo (recorded in manifest), but are not pinned by any of the scanned
+// projects. It returns paths of removed (or to be removed, in dry run mode) binaries.
+func gc(logger *log.Logger, c gcConfig) (removed []string, _ error) {
+	if c.dryRun {
+		bins, err := bingo.ReadManifest(c.manifestFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "read manifest")
+		}
+		_, removed, err = gcBinaries(logger, c, bins)
+		return removed, err
+	}
+
+	// Manifest is locked while binaries are removed, so binaries built in the meantime are not forgotten.
+	if err := bingo.UpdateManifest(c.manifestFile, func(bins []bingo.BuiltBinary) (kept []bingo.BuiltBinary, err error) {
+		kept, removed, err = gcBinaries(logger, c, bins)
+		return kept, err
+	}); err != nil {
+		return nil, errors.Wrap(err, "update manifest")
+	}
+	return removed, nil
+}
+
+// gcBinaries removes not pinned binaries from the given ones, unless in dry run mode. It returns binaries to keep in the
+// manifest and paths of removed (or to be removed, in dry run mode) binaries.
+func gcBinaries(logger *log.Logger, c gcConfig, bins []bingo.BuiltBinary) (_ []bingo.BuiltBinary, removed []string, _ error) {
+	var (
+		kept       []bingo.BuiltBinary
+		candidates = map[string][]bingo.BuiltBinary{}
+	)
+	for _, b := range bins {
+		if _, err := os.Stat(b.Path); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, nil, err
+			}
+			// Removed by someone else, forget about it.
+			continue
+		}
+
+		_, isPinned := c.pinned[filepath.Base(b.Path)]
+		if filepath.Dir(b.Path) != c.gobinDir || isPinned || c.now.Sub(b.BuiltAt) < c.minAge {
+			kept = append(kept, b)
+			continue
+		}
+		candidates[b.Name] = append(candidates[b.Name], b)
+	}
+
+	names := make([]string, 0, len(candidates))
+	for n := range candidates {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		cands := candidates[n]
+		// Newest first, so we can keep last N built ones.
+		sort.SliceStable(cands, func(i, j int) bool { return cands[i].BuiltAt.After(cands[j].BuiltAt) })
+		keep := c.keepLast
+		if keep > len(cands) {
+			keep = len(cands)
+		}
+		kept = append(kept, cands[:keep]...)
+
+		for _, b := range cands[keep:] {
+			removed = append(removed, b.Path)
+			if c.dryRun {
+				kept = append(kept, b)
+				continue
+			}
+
+			if c.verbose {
+				logger.Println("removing", b.Path)
+			}
+			if err := os.RemoveAll(b.Path); err != nil {
+				return nil, nil, errors.Wrap(err, "rm")
+			}
+
+			link := filepath.Join(c.gobinDir, b.Name)
+			ok, err := isLinkTo(link, b.Path)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "check link %v", link)
+			}
+			if ok {
+				if err := os.RemoveAll(link); err != nil {
+					return nil, nil, errors.Wrap(err, "rm")
+				}
+			}
+		}
+	}
+
+	return kept, removed, nil
+}
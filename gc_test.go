@@ -0,0 +1,75 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestGc(t *testing.T) {
+	logger := log.New(os.Stderr, "", 0)
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (gobinDir, manifestFile string) {
+		gobinDir, otherGobinDir := t.TempDir(), t.TempDir()
+		manifestFile = filepath.Join(t.TempDir(), "binaries.jsonl")
+
+		for i, b := range []string{"faillint-v1.0.0", "faillint-v1.1.0", "faillint-v1.2.0", "faillint-v1.5.0", "goimports-v0.1.0"} {
+			testutil.Ok(t, os.WriteFile(filepath.Join(gobinDir, b), nil, os.ModePerm))
+			testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{
+				Path:    filepath.Join(gobinDir, b),
+				Name:    b[:len(b)-len("-v1.0.0")],
+				BuiltAt: now.Add(time.Duration(i-5) * 24 * time.Hour),
+			}))
+		}
+		// Rebuilt, so the latest entry should win.
+		testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{Path: filepath.Join(gobinDir, "faillint-v1.0.0"), Name: "faillint", BuiltAt: now}))
+		// Binary from other GOBIN and binary already removed by user.
+		testutil.Ok(t, os.WriteFile(filepath.Join(otherGobinDir, "faillint-v0.1.0"), nil, os.ModePerm))
+		testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{Path: filepath.Join(otherGobinDir, "faillint-v0.1.0"), Name: "faillint"}))
+		testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{Path: filepath.Join(gobinDir, "faillint-v0.0.1"), Name: "faillint"}))
+		// Not built by bingo.
+		testutil.Ok(t, os.WriteFile(filepath.Join(gobinDir, "faillint-v0.2.0"), nil, os.ModePerm))
+		testutil.Ok(t, os.Symlink(filepath.Join(gobinDir, "faillint-v1.1.0"), filepath.Join(gobinDir, "faillint")))
+		return gobinDir, manifestFile
+	}
+	pinned := map[string]struct{}{"faillint-v1.5.0": {}}
+
+	t.Run("dry run", func(t *testing.T) {
+		gobinDir, manifestFile := setup(t)
+		removed, err := gc(logger, gcConfig{gobinDir: gobinDir, manifestFile: manifestFile, pinned: pinned, dryRun: true, now: now})
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{
+			filepath.Join(gobinDir, "faillint-v1.0.0"),
+			filepath.Join(gobinDir, "faillint-v1.2.0"),
+			filepath.Join(gobinDir, "faillint-v1.1.0"),
+			filepath.Join(gobinDir, "goimports-v0.1.0"),
+		}, removed)
+		testutil.Equals(t, []string{"faillint", "faillint-v0.2.0", "faillint-v1.0.0", "faillint-v1.1.0", "faillint-v1.2.0", "faillint-v1.5.0", "goimports-v0.1.0"}, dirFiles(t, gobinDir))
+	})
+	t.Run("keep last and min age", func(t *testing.T) {
+		gobinDir, manifestFile := setup(t)
+		removed, err := gc(logger, gcConfig{gobinDir: gobinDir, manifestFile: manifestFile, pinned: pinned, keepLast: 1, minAge: 36 * time.Hour, now: now})
+		testutil.Ok(t, err)
+		// faillint-v1.0.0 was rebuilt recently, goimports-v0.1.0 is too young and faillint-v1.2.0 is the last built faillint.
+		testutil.Equals(t, []string{filepath.Join(gobinDir, "faillint-v1.1.0")}, removed)
+		testutil.Equals(t, []string{"faillint-v0.2.0", "faillint-v1.0.0", "faillint-v1.2.0", "faillint-v1.5.0", "goimports-v0.1.0"}, dirFiles(t, gobinDir))
+
+		bins, err := bingo.ReadManifest(manifestFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, 5, len(bins))
+	})
+}
+
+func TestProjectModDir(t *testing.T) {
+	testutil.Equals(t, filepath.Join("repos", "thanos", ".bingo"), projectModDir(filepath.Join("repos", "thanos"), ".bingo"))
+	testutil.Equals(t, "/tmp/tools/.bingo", projectModDir(filepath.Join("repos", "thanos"), "/tmp/tools/.bingo"))
+}
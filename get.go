@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,18 +20,129 @@ import (
 
 	"github.com/Masterminds/semver"
 	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
 	"github.com/bwplotka/bingo/pkg/mod"
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/bwplotka/bingo/pkg/version"
 	"github.com/efficientgo/core/errcapture"
 	"github.com/efficientgo/core/errors"
 	"golang.org/x/mod/module"
+	modsemver "golang.org/x/mod/semver"
 )
 
 var (
 	goModVersionRegexp = regexp.MustCompile("^v[0-9]*$")
+	commitSHARegexp    = regexp.MustCompile("^[0-9a-f]{7,40}$")
 )
 
+// isVersionConstraint returns true if given version is a semver range (e.g. "~1.54", "1.54.x" or ">=1.2, <1.5"),
+// not an exact version, commit SHA or special query like "latest".
+func isVersionConstraint(v string) bool {
+	if v == "" || v == "latest" || v == "none" || modsemver.IsValid(v) || commitSHARegexp.MatchString(v) {
+		return false
+	}
+	_, err := semver.NewConstraint(v)
+	return err == nil
+}
+
+// isTrackable returns true if given version looks like Git branch or tag name that can move,
+// not an exact version, commit SHA, semver range or special query like "latest".
+func isTrackable(v string) bool {
+	return v != "" && v != "latest" && v != "none" && !modsemver.IsValid(v) && !commitSHARegexp.MatchString(v) && !isVersionConstraint(v)
+}
+
+// newestMatching returns the newest version from the given ones that satisfies given constraint.
+func newestMatching(constraint string, versions []string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse version constraint %q", constraint)
+	}
+
+	var newest *semver.Version
+	var ret string
+	for _, v := range versions {
+		sv, err := semver.NewVersion(v)
+		if err != nil || !c.Check(sv) {
+			continue
+		}
+		if newest == nil || sv.GreaterThan(newest) {
+			newest = sv
+			ret = v
+		}
+	}
+	if ret == "" {
+		return "", errors.Newf("no version satisfies constraint %q; available versions: %v", constraint, versions)
+	}
+	return ret, nil
+}
+
+// majorModulePaths returns module paths of all major versions up to the one of the given module path, e.g.
+// github.com/org/tool and github.com/org/tool/v2 for github.com/org/tool/v2. Module path has to be resolved
+// before the constraint is applied, so it can point to any (usually latest) major version, while constraint can select
+// any of them.
+func majorModulePaths(modPath string) []string {
+	prefix, pathMajor, ok := module.SplitPathVersion(modPath)
+	if !ok || !strings.HasPrefix(pathMajor, "/v") {
+		return []string{modPath}
+	}
+	major, err := strconv.Atoi(pathMajor[2:])
+	if err != nil {
+		return []string{modPath}
+	}
+	ret := []string{prefix}
+	for i := 2; i <= major; i++ {
+		ret = append(ret, fmt.Sprintf("%s/v%d", prefix, i))
+	}
+	return ret
+}
+
+// resolveConstraint sets target module path and version to the newest module version satisfying the given constraint,
+// across all major versions of the module. Target module path has to be resolved already.
+func resolveConstraint(runnable runner.Runnable, constraint string, target *bingo.Package) error {
+	var (
+		versions []string
+		paths    = map[string]string{}
+		majors   = majorModulePaths(target.Module.Path)
+	)
+	for _, p := range majors {
+		out, err := runnable.List("-m", "-versions", p)
+		if err != nil {
+			if p == target.Module.Path {
+				return errors.Wrapf(err, "list versions of %v", p)
+			}
+			// Not every major version has to exist (e.g. v1 released under different path).
+			continue
+		}
+		fields := strings.Fields(out)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, v := range fields[1:] {
+			if len(majors) > 1 && strings.HasSuffix(v, "+incompatible") {
+				// Released properly under major version suffixed path.
+				continue
+			}
+			versions = append(versions, v)
+			paths[v] = p
+		}
+	}
+	if len(versions) == 0 {
+		return errors.Newf("no tagged versions found for %v, required to satisfy version constraint %q", target.Module.Path, constraint)
+	}
+
+	v, err := newestMatching(constraint, versions)
+	if err != nil {
+		return errors.Wrap(err, target.Module.Path)
+	}
+	target.Module.Path, target.Module.Version = paths[v], v
+
+	// Download chosen version, so directives can be fetched from it.
+	if out, err := runnable.GetD(target.String()); err != nil {
+		return errors.Wrap(err, out)
+	}
+	return nil
+}
+
 func parseTarget(rawTarget string) (name string, pkgPath string, versions []string, err error) {
 	if rawTarget == "" {
 		return "", "", nil, errors.New("target is empty, this should be filtered earlier")
@@ -75,7 +187,9 @@ type installPackageConfig struct {
 	runner    *runner.Runner
 	modDir    string
 	relModDir string
+	bingoCfg  bingo.Config
 	link      bool
+	track     bool
 
 	verbose bool
 }
@@ -84,9 +198,12 @@ type getConfig struct {
 	runner    *runner.Runner
 	modDir    string
 	relModDir string
-	name      string
-	rename    string
-	link      bool
+	// bingoCfg is the project level configuration loaded from the mod directory.
+	bingoCfg bingo.Config
+	name     string
+	rename   string
+	link     bool
+	track    bool
 
 	timeOut uint
 	verbose bool
@@ -96,9 +213,11 @@ func (c getConfig) forPackage() installPackageConfig {
 	return installPackageConfig{
 		modDir:    c.modDir,
 		relModDir: c.relModDir,
+		bingoCfg:  c.bingoCfg,
 		runner:    c.runner,
 		verbose:   c.verbose,
 		link:      c.link,
+		track:     c.track,
 	}
 }
 
@@ -116,6 +235,10 @@ func getAll(ctx context.Context, logger *log.Logger, c getConfig) (err error) {
 	}
 	for _, p := range pkgs {
 		for i, targetPkg := range p.ToPackages() {
+			if t := p.Versions[i].Track; t != "" {
+				// Re-resolve tracked branch or tag head.
+				targetPkg.Module.Version = t
+			}
 			if err := getPackage(ctx, logger, c.forPackage(), i, p.Name, targetPkg); err != nil {
 				return errors.Wrapf(err, "%d: getting %s", i, targetPkg.String())
 			}
@@ -151,9 +274,13 @@ func get(ctx context.Context, logger *log.Logger, c getConfig, rawTarget string)
 	if err := cleanGoGetTmpFiles(c.modDir); err != nil {
 		return err
 	}
-	if err := ensureModDirExists(logger, c.relModDir); err != nil {
+	if err := ensureModDirExists(logger, c.relModDir, c.bingoCfg); err != nil {
 		return errors.Wrap(err, "ensure mod dir")
 	}
+	// Mod directory could be just created together with configuration for new projects.
+	if c.bingoCfg, err = bingo.LoadConfig(c.modDir); err != nil {
+		return errors.Wrap(err, "load config")
+	}
 
 	if rawTarget == "" {
 		// Empty target means to get all. It recursively invokes get for each existing binary.
@@ -166,6 +293,17 @@ func get(ctx context.Context, logger *log.Logger, c getConfig, rawTarget string)
 		return errors.Wrapf(err, "parse %v", rawTarget)
 	}
 
+	if c.track {
+		if c.rename != "" {
+			return errors.New("--track cannot be used with -r")
+		}
+		for _, v := range versions {
+			if !isTrackable(v) {
+				return errors.Newf("--track requires Git branch or tag name after @ (e.g. %v@main), got %q", name, v)
+			}
+		}
+	}
+
 	if c.rename != "" {
 		// Treat rename specially.
 		if pkgPath != "" {
@@ -284,8 +422,11 @@ func get(ctx context.Context, logger *log.Logger, c getConfig, rawTarget string)
 
 				target.Module.Path = dpkg.Module.Path
 				if target.Module.Version == "" {
-					// If no version is requested, use the existing version.
+					// If no version is requested, use the existing version or re-resolve tracked branch or tag head.
 					target.Module.Version = dpkg.Module.Version
+					if t := mf.Track(); t != "" {
+						target.Module.Version = t
+					}
 				}
 				target.RelPath = dpkg.RelPath
 
@@ -605,9 +746,45 @@ func getPackage(ctx context.Context, logger *log.Logger, c installPackageConfig,
 
 	outSumFile := strings.TrimSuffix(outModFile, ".mod") + ".sum"
 
+	var existingConstraint, existingTrack string
+	if _, err := os.Stat(outModFile); err == nil {
+		mf, err := bingo.OpenModFile(outModFile)
+		if err != nil {
+			return errors.Wrapf(err, "open %v", outModFile)
+		}
+		existingConstraint, existingTrack = mf.Constraint(), mf.Track()
+		if err := mf.Close(); err != nil {
+			return err
+		}
+	}
+
+	// Tracked branch or tag is re-resolved to its head. Getting any other version stops tracking.
+	var track string
+	if c.track || (existingTrack != "" && target.Module.Version == existingTrack) {
+		track = target.Module.Version
+	}
+
+	// Version constraint can be given as version or declared in existing mod file. It's applied on each upgrade (@latest).
+	var constraint, newConstraint string
+	switch {
+	case track != "":
+	case isVersionConstraint(target.Module.Version):
+		constraint, newConstraint = target.Module.Version, target.Module.Version
+		target.Module.Version = "latest"
+	case target.Module.Version == "latest":
+		constraint = existingConstraint
+	case target.Module.Version != "" && existingConstraint != "":
+		if v, err := semver.NewVersion(target.Module.Version); err == nil {
+			if c, err := semver.NewConstraint(existingConstraint); err == nil && !c.Check(v) {
+				logger.Printf("WARNING: requested version %v of %v does not satisfy version constraint %q declared in %v\n",
+					target.Module.Version, name, existingConstraint, outModFile)
+			}
+		}
+	}
+
 	// If we don't have all information, resolve version.
 	var fetchedDirectives nonRequireDirectives
-	if target.Module.Version == "" || !strings.HasPrefix(target.Module.Version, "v") || target.Module.Path == "" {
+	if target.Module.Version == "" || !strings.HasPrefix(target.Module.Version, "v") || target.Module.Path == "" || track != "" {
 		// Set up totally empty mod file to get clear version to install.
 		tmpEmptyModFile, err := bingo.CreateFromExistingOrNew(ctx, c.runner, logger, "", tmpEmptyModFilePath)
 		if err != nil {
@@ -620,6 +797,11 @@ func getPackage(ctx context.Context, logger *log.Logger, c installPackageConfig,
 		if err := resolvePackage(logger, c.verbose, tmpEmptyModFile.Filepath(), runnable, &target); err != nil {
 			return err
 		}
+		if constraint != "" {
+			if err := resolveConstraint(runnable, constraint, &target); err != nil {
+				return err
+			}
+		}
 
 		if !strings.HasSuffix(target.Module.Version, "+incompatible") {
 			fetchedDirectives, err = autoFetchDirectives(runnable, logger, target)
@@ -668,8 +850,22 @@ func getPackage(ctx context.Context, logger *log.Logger, c installPackageConfig,
 	if err := tmpModFile.SetDirectRequire(target); err != nil {
 		return err
 	}
+	if newConstraint != "" {
+		if err := tmpModFile.SetConstraint(newConstraint); err != nil {
+			return err
+		}
+	}
+	if track != "" || tmpModFile.Track() != "" {
+		if err := tmpModFile.SetTrack(track); err != nil {
+			return err
+		}
+	}
 
-	if err := install(ctx, logger, c.runner, c.modDir, name, c.link, tmpModFile); err != nil {
+	var binsumFile string
+	if c.bingoCfg.Binsum {
+		binsumFile = bingo.BinsumFilePath(outModFile)
+	}
+	if err := install(ctx, logger, c.runner, c.modDir, c.bingoCfg, name, c.link, tmpModFile, binsumFile); err != nil {
 		return errors.Wrap(err, "install")
 	}
 
@@ -776,17 +972,34 @@ func gobin(runnable runner.Runnable) (string, error) {
 	return filepath.Join(gpath, "bin"), nil
 }
 
-func install(ctx context.Context, logger *log.Logger, r *runner.Runner, modDir string, name string, link bool, modFile *bingo.ModFile) (err error) {
+// install builds the tool pinned by the given module file. If binsumFile is not empty, sha256 of the built binary is
+// verified against (or recorded in) it.
+func install(ctx context.Context, logger *log.Logger, r *runner.Runner, modDir string, cfg bingo.Config, name string, link bool, modFile *bingo.ModFile, binsumFile string) (err error) {
 	pkg := modFile.DirectPackage()
 	if err := validateTargetName(name); err != nil {
 		return errors.Wrap(err, pkg.String())
 	}
+	if err := pkg.ValidateBuildOptions(); err != nil {
+		return errors.Wrapf(err, "%v: %v", modFile.Filepath(), pkg.String())
+	}
+
+	// Build environment variables and flags reference the same environment as in the shell helpers: the process one,
+	// cleared for reproducible build and amended by the env file.
+	env := envars.MergeEnvSlices(os.Environ(), cfg.ReproducibleEnv()...)
+	var fileEnvs envars.EnvSlice
+	if envFile := modFile.EnvFile(); envFile != "" {
+		fileEnvs, err = bingo.EvalEnvFile(ctx, modDir, envFile)
+		if err != nil {
+			return err
+		}
+		env = envars.MergeEnvSlices(env, fileEnvs...)
+	}
 
 	modCtx := r.With(ctx, modFile.Filepath(), modDir, nil)
 
 	// Check if path is pointing to non-buildable package.
 	var listArgs []string
-	listArgs = append(listArgs, modFile.DirectPackage().BuildFlags...)
+	listArgs = append(listArgs, pkg.ExpandedBuildFlags(env)...)
 	listArgs = append(listArgs, "-mod=mod", "-f={{.Name}}", pkg.Path())
 	if listOutput, err := modCtx.List(listArgs...); err != nil {
 		return errors.Wrap(err, "list")
@@ -808,9 +1021,20 @@ func install(ctx context.Context, logger *log.Logger, r *runner.Runner, modDir s
 	// go install does not define -modfile flag, so we mimic go install with go build -o instead.
 	binPath := filepath.Join(gobin, fmt.Sprintf("%s-%s", name, pkg.Module.Version))
 
+	// Build environment variables declared inline take precedence over the ones from the env file.
+	buildEnvs := pkg.ExpandedBuildEnvs(env)
+	if fileEnvs != nil {
+		buildEnvs = envars.MergeEnvSlices(fileEnvs, buildEnvs...)
+	}
+	buildFlags := pkg.ExpandedBuildFlags(env)
+	if cfg.Reproducible {
+		buildEnvs, buildFlags = bingo.ReproducibleBuildOptions(buildEnvs, buildFlags)
+		buildEnvs = envars.MergeEnvSlices(cfg.ReproducibleEnv(), buildEnvs...)
+	}
+
 	// New context with new environment files.
-	modCtx = r.With(ctx, modFile.Filepath(), modDir, pkg.BuildEnvs)
-	if err := modCtx.Build(pkg.Path(), binPath, pkg.BuildFlags...); err != nil {
+	modCtx = r.With(ctx, modFile.Filepath(), modDir, buildEnvs)
+	if err := modCtx.Build(pkg.Path(), binPath, buildFlags...); err != nil {
 		if strings.Contains(err.Error(), "module declares its path as: ") &&
 			strings.Contains(err.Error(), fmt.Sprintf("but was required as: %v", modFile.DirectPackage().Path())) {
 
@@ -821,6 +1045,33 @@ func install(ctx context.Context, logger *log.Logger, r *runner.Runner, modDir s
 		return errors.Wrap(err, "build versioned")
 	}
 
+	sum, err := bingo.HashFile(binPath)
+	if err != nil {
+		return errors.Wrap(err, "hash built binary")
+	}
+	if cfg.Reproducible {
+		logger.Printf("built %v reproducibly, sha256: %v\n", filepath.Base(binPath), sum)
+	}
+	if binsumFile != "" {
+		if err := verifyOrRecordBinsum(logger, modCtx, binsumFile, binPath, pkg.Module.Version, sum); err != nil {
+			return err
+		}
+	}
+
+	// Record the binary, so `bingo gc` knows it was built by bingo.
+	if manifestFile, err := bingo.DefaultManifestFile(); err != nil {
+		logger.Println("WARNING: cannot find manifest file; built binary will not be cleaned by 'bingo gc':", err)
+	} else if err := bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{
+		Path:    binPath,
+		Name:    name,
+		Version: pkg.Module.Version,
+		Package: pkg.Path(),
+		BuiltAt: time.Now(),
+		SHA256:  sum,
+	}); err != nil {
+		logger.Println("WARNING: cannot record built binary in manifest; it will not be cleaned by 'bingo gc':", err)
+	}
+
 	if !link {
 		return nil
 	}
@@ -834,6 +1085,40 @@ func install(ctx context.Context, logger *log.Logger, r *runner.Runner, modDir s
 	return nil
 }
 
+// verifyOrRecordBinsum verifies sha256 of the built binary against the binsum file or records it, if missing. Binary
+// not matching the recorded checksum is removed.
+func verifyOrRecordBinsum(logger *log.Logger, modCtx runner.Runnable, binsumFile, binPath, version, sum string) error {
+	platform, err := modCtx.GoEnv("GOOS")
+	if err != nil {
+		return errors.Wrap(err, "go env GOOS")
+	}
+	goarch, err := modCtx.GoEnv("GOARCH")
+	if err != nil {
+		return errors.Wrap(err, "go env GOARCH")
+	}
+	goVersion, err := modCtx.GoEnv("GOVERSION")
+	if err != nil {
+		return errors.Wrap(err, "go env GOVERSION")
+	}
+
+	recorded, err := bingo.VerifyOrRecordBinsum(binsumFile, bingo.BinsumEntry{
+		Version:   version,
+		Platform:  platform + "/" + goarch,
+		GoVersion: goVersion,
+		SHA256:    sum,
+	})
+	if err != nil {
+		if rerr := os.RemoveAll(binPath); rerr != nil {
+			logger.Println("WARNING: cannot remove binary not matching recorded checksum:", rerr)
+		}
+		return errors.Wrap(err, "verify binsum")
+	}
+	if recorded {
+		logger.Printf("recorded sha256 of %v in %v; commit it, so other builds are verified against it\n", filepath.Base(binPath), filepath.Base(binsumFile))
+	}
+	return nil
+}
+
 const modREADMEFmt = `# Project Development Dependencies.
 
 This is directory which stores Go modules with pinned buildable package that is used within this repository, managed by <https://github.com/bwplotka/bingo>.
@@ -842,6 +1127,7 @@ This is directory which stores Go modules with pinned buildable package that is
 * Run ` + "`" + "bingo get <tool>" + "`" + ` to install ` + "`" + "<tool>" + "`" + ` that have own module file in this directory.
 * For Makefile: Make sure to put ` + "`" + "include %s/Variables.mk" + "`" + ` in your Makefile, then use ` + "`" + "\\$(<UPPER_CASE_TOOL_NAME>)" + "`" + ` variable where ` + "`" + "<tool>" + "`" + ` is the %s/` + "`" + "<tool>.mod" + "`" + `.
 * For shell: Run ` + "`" + "source %s/variables.env" + "`" + ` to source all environment variable for each tool.
+* For justfile: Make sure to put ` + "`" + "import '%s/tools.just'" + "`" + ` in your justfile, then use ` + "`" + "{{<UPPER_CASE_TOOL_NAME>}}" + "`" + ` variable in recipes depending on ` + "`" + "install-<tool>" + "`" + ` recipe.
 * See <https://github.com/bwplotka/bingo> or -h on how to add, remove or change binaries dependencies.
 
 ## Requirements
@@ -849,7 +1135,9 @@ This is directory which stores Go modules with pinned buildable package that is
 * Go 1.24.x or 1.25.x
 `
 
-const gitignore = `
+// gitignoreFmt is a .gitignore template for the mod directory. Files generated and used by enabled helpers and env
+// files referenced by pinned tools are appended to the list of not ignored files.
+const gitignoreFmt = `
 # Ignore everything
 *
 
@@ -857,15 +1145,45 @@ const gitignore = `
 !.gitignore
 !*.mod
 !*.sum
+!*.binsum
+!*.env
 !README.md
-!Variables.mk
-!variables.env
-
+!%s
+%s
 *tmp.mod
 *tmp.sum
 `
 
-func ensureModDirExists(logger *log.Logger, relModDir string) error {
+func gitignore(cfg bingo.Config, pkgs []bingo.PackageRenderable) string {
+	var (
+		helperFiles strings.Builder
+		dirs        = map[string]struct{}{}
+		files       = cfg.HelperFiles()
+	)
+	for _, p := range pkgs {
+		// Env files outside of the mod directory are not ignored by it.
+		if p.EnvFile != "" && path.Ext(p.EnvFile) != ".env" && filepath.IsLocal(filepath.FromSlash(p.EnvFile)) && !slices.Contains(files, path.Clean(p.EnvFile)) {
+			files = append(files, path.Clean(p.EnvFile))
+		}
+	}
+	for _, f := range files {
+		// Parent directories have to be included explicitly, otherwise files inside are ignored.
+		var parents []string
+		for d := path.Dir(f); d != "."; d = path.Dir(d) {
+			parents = append([]string{d}, parents...)
+		}
+		for _, d := range parents {
+			if _, ok := dirs[d]; !ok {
+				dirs[d] = struct{}{}
+				helperFiles.WriteString("!" + d + "/\n")
+			}
+		}
+		helperFiles.WriteString("!" + f + "\n")
+	}
+	return fmt.Sprintf(gitignoreFmt, bingo.ConfigFileName, helperFiles.String())
+}
+
+func ensureModDirExists(logger *log.Logger, relModDir string, cfg bingo.Config) error {
 	_, err := os.Stat(relModDir)
 	if err != nil {
 		if !os.IsNotExist(err) {
@@ -876,6 +1194,10 @@ func ensureModDirExists(logger *log.Logger, relModDir string) error {
 		if err := os.MkdirAll(relModDir, os.ModePerm); err != nil {
 			return errors.Wrapf(err, "create moddir %s", relModDir)
 		}
+		// New projects build tools reproducibly by default.
+		if err := os.WriteFile(filepath.Join(relModDir, bingo.ConfigFileName), []byte(bingo.NewProjectConfig), 0666); err != nil {
+			return err
+		}
 	}
 
 	// Hack against:
@@ -893,13 +1215,21 @@ func ensureModDirExists(logger *log.Logger, relModDir string) error {
 	// README.
 	if err := os.WriteFile(
 		filepath.Join(relModDir, "README.md"),
-		[]byte(fmt.Sprintf(modREADMEFmt, relModDir, relModDir, relModDir)),
+		[]byte(fmt.Sprintf(modREADMEFmt, relModDir, relModDir, relModDir, relModDir)),
 		0666,
 	); err != nil {
 		return err
 	}
-	// gitignore.
-	return os.WriteFile(filepath.Join(relModDir, ".gitignore"), []byte(gitignore), 0666)
+	pkgs, err := bingo.ListPinnedMainPackages(logger, relModDir, false)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	return writeGitignore(relModDir, cfg, pkgs)
+}
+
+// writeGitignore writes .gitignore of the mod directory.
+func writeGitignore(relModDir string, cfg bingo.Config, pkgs []bingo.PackageRenderable) error {
+	return os.WriteFile(filepath.Join(relModDir, ".gitignore"), []byte(gitignore(cfg, pkgs)), 0666)
 }
 
 func removeAllGlob(glob string) error {
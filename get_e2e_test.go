@@ -647,8 +647,8 @@ func TestExpectBingoListRows(t *testing.T) {
 		{name: "mdox", binName: "mdox-v0.2.1", pkgVersion: "github.com/bwplotka/mdox@v0.2.1"},
 		{name: "misspell", binName: "misspell-v0.3.4", pkgVersion: "github.com/client9/misspell/cmd/misspell@v0.3.4"},
 		{name: "proxy", binName: "proxy-v0.10.0", pkgVersion: "github.com/gomods/athens/cmd/proxy@v0.10.0"},
-	}, `Name		Binary Name					Package @ Version								Build EnvVars	Build Flags
-----		-----------					-----------------								-------------	-----------
+	}, `Name		Binary Name					Package @ Version								Build EnvVars	Build Flags	Tracking
+----		-----------					-----------------								-------------	-----------	--------
 copyright	copyright-v0.0.0-20210112004814-138d5e5695fe	github.com/efficientgo/tools/copyright@v0.0.0-20210112004814-138d5e5695fe			
 embedmd		embedmd-v1.0.0					github.com/campoy/embedmd@v1.0.0						CGO_ENABLED=1	-tags=lol
 faillint	faillint-v1.5.0					github.com/fatih/faillint@v1.5.0								
@@ -4,10 +4,15 @@
 package main
 
 import (
+	"os/exec"
+	"strings"
 	"testing"
 
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/efficientgo/core/errors"
 	"github.com/efficientgo/core/testutil"
+	"golang.org/x/mod/module"
 )
 
 func TestParseTarget(t *testing.T) {
@@ -89,3 +94,169 @@ func TestParseTarget(t *testing.T) {
 	}
 
 }
+
+func TestIsVersionConstraint(t *testing.T) {
+	for v, expected := range map[string]bool{
+		"":                                   false,
+		"latest":                             false,
+		"none":                               false,
+		"v1.54.2":                            false,
+		"v0.0.0-20200519204825-e64124511800": false,
+		"e64124511800702a4d8d79e04cf6f1af32e7bef2": false,
+		"4040b74":     false,
+		"main":        false,
+		"~1.54":       true,
+		"1.54.x":      true,
+		"^1":          true,
+		">=1.2, <1.5": true,
+		"< v2.0.0":    true,
+	} {
+		t.Run(v, func(t *testing.T) {
+			testutil.Equals(t, expected, isVersionConstraint(v))
+		})
+	}
+}
+
+func TestNewestMatching(t *testing.T) {
+	versions := []string{"v1.53.3", "v1.54.0", "v1.54.2", "v1.55.0-rc.1", "v1.55.2", "v2.0.0+incompatible"}
+
+	for _, tcase := range []struct {
+		constraint string
+
+		expected    string
+		expectedErr bool
+	}{
+		{constraint: "~1.54", expected: "v1.54.2"},
+		{constraint: "1.54.x", expected: "v1.54.2"},
+		{constraint: "<1.55", expected: "v1.54.2"},
+		{constraint: "^1", expected: "v1.55.2"},
+		{constraint: ">1", expected: "v2.0.0+incompatible"},
+		{constraint: "~1.60", expectedErr: true},
+		{constraint: "not a constraint", expectedErr: true},
+	} {
+		t.Run(tcase.constraint, func(t *testing.T) {
+			v, err := newestMatching(tcase.constraint, versions)
+			if tcase.expectedErr {
+				testutil.NotOk(t, err)
+				return
+			}
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, v)
+		})
+	}
+}
+
+// versionsRunnable is a runner.Runnable listing module versions from the given map.
+type versionsRunnable struct {
+	runner.Runnable
+
+	versions map[string][]string
+	got      []string
+}
+
+func (r *versionsRunnable) List(args ...string) (string, error) {
+	p := args[len(args)-1]
+	v, ok := r.versions[p]
+	if !ok {
+		return "", errors.Newf("module %v: not found", p)
+	}
+	return strings.Join(append([]string{p}, v...), " "), nil
+}
+
+func (r *versionsRunnable) GetD(packages ...string) (string, error) {
+	r.got = append(r.got, packages...)
+	return "", nil
+}
+
+func TestResolveConstraint(t *testing.T) {
+	r := &versionsRunnable{versions: map[string][]string{
+		"github.com/golangci/golangci-lint":    {"v1.54.0", "v1.54.2", "v1.55.0", "v2.0.0+incompatible"},
+		"github.com/golangci/golangci-lint/v2": {"v2.0.0", "v2.1.0"},
+		// No v3 released.
+		"github.com/golangci/golangci-lint/v4": {"v4.0.0"},
+	}}
+
+	for _, tcase := range []struct {
+		constraint string
+
+		expected    module.Version
+		expectedErr bool
+	}{
+		{constraint: "~1.54", expected: module.Version{Path: "github.com/golangci/golangci-lint", Version: "v1.54.2"}},
+		{constraint: "^2", expected: module.Version{Path: "github.com/golangci/golangci-lint/v2", Version: "v2.1.0"}},
+		{constraint: ">=2.0.0, <2.1", expected: module.Version{Path: "github.com/golangci/golangci-lint/v2", Version: "v2.0.0"}},
+		{constraint: ">1", expected: module.Version{Path: "github.com/golangci/golangci-lint/v4", Version: "v4.0.0"}},
+		{constraint: "~3", expectedErr: true},
+	} {
+		t.Run(tcase.constraint, func(t *testing.T) {
+			// Latest resolves to the newest major version path.
+			target := bingo.Package{Module: module.Version{Path: "github.com/golangci/golangci-lint/v4", Version: "latest"}, RelPath: "cmd/golangci-lint"}
+			err := resolveConstraint(r, tcase.constraint, &target)
+			if tcase.expectedErr {
+				testutil.NotOk(t, err)
+				return
+			}
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, target.Module)
+			testutil.Equals(t, tcase.expected.Path+"/cmd/golangci-lint@"+tcase.expected.Version, r.got[len(r.got)-1])
+		})
+	}
+
+	testutil.Equals(t, []string{"gopkg.in/yaml.v3"}, majorModulePaths("gopkg.in/yaml.v3"))
+	testutil.Equals(t, []string{"github.com/org/tool"}, majorModulePaths("github.com/org/tool"))
+}
+
+func TestIsTrackable(t *testing.T) {
+	for v, expected := range map[string]bool{
+		"":        false,
+		"latest":  false,
+		"none":    false,
+		"v1.54.2": false,
+		"~1.54":   false,
+		"e64124511800702a4d8d79e04cf6f1af32e7bef2": false,
+		"main":         true,
+		"release-1.2":  true,
+		"feature/yolo": true,
+		"nightly":      true,
+	} {
+		t.Run(v, func(t *testing.T) {
+			testutil.Equals(t, expected, isTrackable(v))
+		})
+	}
+}
+
+func TestGitignore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	modDir := t.TempDir()
+	testutil.Ok(t, exec.Command("git", "-C", modDir, "init", "-q").Run())
+
+	cfg := bingo.Config{Helpers: []string{"mk", "taskfile"}}
+	pkgs := []bingo.PackageRenderable{
+		{Name: "faillint", EnvFile: "build.env"},
+		{Name: "golangci-lint", EnvFile: "env/lint.sh"},
+		{Name: "protoc", EnvFile: "../protoc.env"},
+	}
+	testutil.Ok(t, writeGitignore(modDir, cfg, pkgs))
+
+	for f, ignored := range map[string]bool{
+		"faillint.mod":         false,
+		"faillint.binsum":      false,
+		"bingo.yaml":           false,
+		"Variables.mk":         false,
+		"Taskfile.yml":         false,
+		"build.env":            false,
+		"other.env":            false,
+		"env/lint.sh":          false,
+		"env/other.sh":         true,
+		"faillint.tmp.mod":     true,
+		"variables.env.backup": true,
+	} {
+		t.Run(f, func(t *testing.T) {
+			// Exit code 1 means the file is not ignored.
+			err := exec.Command("git", "-C", modDir, "check-ignore", "-q", "--no-index", f).Run()
+			testutil.Equals(t, ignored, err == nil)
+		})
+	}
+}
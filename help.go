@@ -0,0 +1,69 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/makefile"
+)
+
+// printTargetsHelp prints all targets documented with comments in the given makefile nodes and pinned tools available
+// through the helper file (e.g. .bingo/Variables.mk).
+func printTargetsHelp(w io.Writer, nodes []makefile.Node, helperFile string, pkgs []bingo.PackageRenderable) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	// Targets documented with "## <comment>" (awk-based help convention) ignore comments above them.
+	inline := map[string]struct{}{}
+	makefile.Walk(nodes, func(n makefile.Node) bool {
+		if c, ok := n.(makefile.Comment); ok && c.Inline {
+			inline[c.Target] = struct{}{}
+		}
+		return true
+	})
+
+	var documented bool
+	makefile.Walk(nodes, func(n makefile.Node) bool {
+		c, ok := n.(makefile.Comment)
+		if !ok || c.Target == "" {
+			return true
+		}
+		if _, ok := inline[c.Target]; ok && !c.Inline {
+			return true
+		}
+		if !documented {
+			_, _ = fmt.Fprintln(tw, "Targets:")
+			documented = true
+		}
+
+		lines := strings.Split(c.Value, "\n")
+		if c.Default {
+			lines[0] += " (default)"
+		}
+		_, _ = fmt.Fprintf(tw, "  %v\t%v\n", c.Target, lines[0])
+		for _, l := range lines[1:] {
+			_, _ = fmt.Fprintf(tw, "  \t%v\n", l)
+		}
+		return true
+	})
+	if !documented {
+		_, _ = fmt.Fprintln(tw, "No documented targets. Document a target with a comment directly above it or with '## <comment>' after it.")
+	}
+
+	if len(pkgs) > 0 {
+		_, _ = fmt.Fprintf(tw, "\nTools (%v):\n", helperFile)
+		for _, p := range pkgs {
+			versions := make([]string, 0, len(p.Versions))
+			for _, v := range p.Versions {
+				versions = append(versions, v.Version)
+			}
+			_, _ = fmt.Fprintf(tw, "  $(%v)\t%v@%v\n", p.EnvVarName, p.PackagePath, strings.Join(versions, ","))
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/makefile"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestPrintTargetsHelp(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(".bingo", "Variables.mk"), []byte("FAILLINT := $(GOBIN)/faillint-v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, os.WriteFile("build.mk", []byte("# Build binaries.\n#\n# Set GOOS to cross-compile.\nbuild:\n\tgo build ./...\n"), os.ModePerm))
+	testutil.Ok(t, os.WriteFile("Makefile", []byte(`include .bingo/Variables.mk
+.DEFAULT_GOAL := lint
+
+# Not a documentation of inline documented target.
+lint: $(FAILLINT) ## Run linters.
+	$(FAILLINT) ./...
+
+internal:
+	@echo
+
+include build.mk
+`), os.ModePerm))
+
+	nodes, err := makefile.ParseFileRecursive("Makefile")
+	testutil.Ok(t, err)
+
+	b := bytes.Buffer{}
+	testutil.Ok(t, printTargetsHelp(&b, nodes, ".bingo/Variables.mk", []bingo.PackageRenderable{
+		{Name: "faillint", PackagePath: "github.com/fatih/faillint", EnvVarName: "FAILLINT", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}},
+		{Name: "arr", PackagePath: "github.com/example/arr", EnvVarName: "ARR_ARRAY", Versions: []bingo.PackageVersionRenderable{{Version: "v1.0.0"}, {Version: "v2.0.0"}}},
+	}))
+	testutil.Equals(t, `Targets:
+  lint   Run linters. (default)
+  build  Build binaries.
+         
+         Set GOOS to cross-compile.
+
+Tools (.bingo/Variables.mk):
+  $(FAILLINT)   github.com/fatih/faillint@v1.5.0
+  $(ARR_ARRAY)  github.com/example/arr@v1.0.0,v2.0.0
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, printTargetsHelp(&b, nil, ".bingo/Variables.mk", nil))
+	testutil.Equals(t, "No documented targets. Document a target with a comment directly above it or with '## <comment>' after it.\n", b.String())
+}
@@ -0,0 +1,156 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/makefile"
+	"github.com/efficientgo/core/errors"
+)
+
+// makefileIncludes returns all files included by the given makefile, also recursively, relative to the dir. Includes that
+// do not exist (yet) or use variables are returned, but not followed. Includes in conditionals are always followed.
+func makefileIncludes(mkFile, dir string, seen map[string]struct{}) ([]string, error) {
+	b, err := os.ReadFile(mkFile)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := makefile.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %v", mkFile)
+	}
+
+	var includes []string
+	makefile.Walk(nodes, func(n makefile.Node) bool {
+		inc, ok := n.(makefile.Include)
+		if !ok {
+			return true
+		}
+		for _, f := range inc.Files() {
+			f = filepath.Clean(strings.TrimPrefix(strings.TrimPrefix(f, "$(CURDIR)/"), "${CURDIR}/"))
+			includes = append(includes, f)
+
+			if _, ok := seen[f]; ok || strings.Contains(f, "$") {
+				continue
+			}
+			seen[f] = struct{}{}
+
+			path := f
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, f)
+			}
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			nested, nerr := makefileIncludes(path, dir, seen)
+			if nerr != nil {
+				err = errors.Wrapf(nerr, "parse included %v", f)
+				return false
+			}
+			includes = append(includes, nested...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return includes, nil
+}
+
+// includeInsertionLine returns index of the line the include directive should be inserted before: right after the last
+// top level include, or before the first top level rule (and its comment), or at the end. It's true if the directive
+// is inserted before a rule. Only positions of top level nodes are used, so the directive never lands inside a
+// conditional, define, recipe or continued line.
+func includeInsertionLine(nodes []makefile.Node, lines []string) (int, bool) {
+	lastInclude := -1
+	for i, n := range nodes {
+		if _, ok := n.(makefile.Include); ok {
+			lastInclude = i
+		}
+	}
+	if lastInclude >= 0 {
+		i := nodes[lastInclude].Pos().Line - 1
+		for i < len(lines)-1 && isContinuedLine(lines[i]) {
+			i++
+		}
+		return i + 1, false
+	}
+
+	for i, n := range nodes {
+		if !definesRule(n) {
+			continue
+		}
+		line := n.Pos().Line - 1
+		for j := i - 1; j >= 0; j-- {
+			c, ok := nodes[j].(makefile.Comment)
+			if !ok || c.Target == "" {
+				break
+			}
+			line = c.Pos().Line - 1
+		}
+		return line, true
+	}
+	return len(lines), false
+}
+
+// definesRule returns true if the node is (or the conditional contains) a rule with other than special targets (e.g. .PHONY).
+func definesRule(n makefile.Node) bool {
+	return !makefile.Walk([]makefile.Node{n}, func(n makefile.Node) bool {
+		r, ok := n.(makefile.Rule)
+		return !ok || len(r.Targets) == 0 || strings.HasPrefix(r.Targets[0], ".")
+	})
+}
+
+// isContinuedLine returns true if the line ends with odd number of backslashes.
+func isContinuedLine(l string) bool {
+	l = strings.TrimSuffix(l, "\r")
+	return (len(l)-len(strings.TrimRight(l, "\\")))%2 == 1
+}
+
+// initMakefile ensures given makefile includes the given helper (directly or via nested includes). It returns line number
+// (starting from 1) of the inserted include directive or 0 if nothing had to be changed. Not existing makefile is created.
+// The -include directive is used, so the makefile works also before the helper is generated by the first 'bingo get'.
+func initMakefile(mkFile, include string) (line int, _ error) {
+	directive := "-include " + filepath.ToSlash(include)
+
+	if _, err := os.Stat(mkFile); err != nil {
+		if !os.IsNotExist(err) {
+			return 0, err
+		}
+		return 1, os.WriteFile(mkFile, []byte(directive+"\n"), 0666)
+	}
+
+	includes, err := makefileIncludes(mkFile, filepath.Dir(mkFile), map[string]struct{}{})
+	if err != nil {
+		return 0, err
+	}
+	for _, inc := range includes {
+		if inc == filepath.Clean(include) {
+			return 0, nil
+		}
+	}
+
+	b, err := os.ReadFile(mkFile)
+	if err != nil {
+		return 0, err
+	}
+	nodes, err := makefile.Parse(bytes.NewReader(b))
+	if err != nil {
+		return 0, errors.Wrapf(err, "parse %v", mkFile)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	i, beforeRule := includeInsertionLine(nodes, lines)
+
+	insert := []string{directive}
+	if beforeRule {
+		// Separate from the following rule.
+		insert = append(insert, "")
+	}
+	lines = append(lines[:i], append(insert, lines[i:]...)...)
+	return i + 1, os.WriteFile(mkFile, []byte(strings.Join(lines, "\n")+"\n"), 0666)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestInitMakefile(t *testing.T) {
+	for _, tcase := range []struct {
+		name     string
+		makefile string
+		other    map[string]string
+		include  string
+
+		expectedLine     int
+		expectedMakefile string
+	}{
+		{
+			name:             "no makefile",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     1,
+			expectedMakefile: "-include .bingo/Variables.mk\n",
+		},
+		{
+			name:             "already included",
+			makefile:         "include .bingo/Variables.mk\n\nall:\n\t$(GOLANGCI_LINT) run\n",
+			include:          ".bingo/Variables.mk",
+			expectedMakefile: "include .bingo/Variables.mk\n\nall:\n\t$(GOLANGCI_LINT) run\n",
+		},
+		{
+			name:             "already included without trailing new line",
+			makefile:         "-include $(CURDIR)/.bingo/Variables.mk",
+			include:          ".bingo/Variables.mk",
+			expectedMakefile: "-include $(CURDIR)/.bingo/Variables.mk",
+		},
+		{
+			name:             "already included in nested makefile",
+			makefile:         "include build/common.mk\n\nall:\n",
+			other:            map[string]string{"build/common.mk": "include build/tools.mk other.mk\n", "build/tools.mk": "sinclude ./.bingo/Variables.mk\n"},
+			include:          ".bingo/Variables.mk",
+			expectedMakefile: "include build/common.mk\n\nall:\n",
+		},
+		{
+			name:             "after last include",
+			makefile:         "GO ?= go\ninclude a.mk\ninclude b.mk\n\nall:\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     4,
+			expectedMakefile: "GO ?= go\ninclude a.mk\ninclude b.mk\n-include .bingo/Variables.mk\n\nall:\n",
+		},
+		{
+			name:             "before first rule and its comment",
+			makefile:         "GO ?= go\nFILES := $(shell ls):x\n.PHONY: all\n# all builds everything.\nall: build\n\t@echo\n",
+			include:          "tools/.bingo/Variables.mk",
+			expectedLine:     4,
+			expectedMakefile: "GO ?= go\nFILES := $(shell ls):x\n.PHONY: all\n-include tools/.bingo/Variables.mk\n\n# all builds everything.\nall: build\n\t@echo\n",
+		},
+		{
+			name:             "include in conditional is not top level",
+			makefile:         "ifeq ($(CI),true)\ninclude ci.mk\nendif\n\nall:\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     5,
+			expectedMakefile: "ifeq ($(CI),true)\ninclude ci.mk\nendif\n\n-include .bingo/Variables.mk\n\nall:\n",
+		},
+		{
+			name:             "after continued include",
+			makefile:         "include a.mk \\\n\tb.mk\nGO ?= go\nall:\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     3,
+			expectedMakefile: "include a.mk \\\n\tb.mk\n-include .bingo/Variables.mk\nGO ?= go\nall:\n",
+		},
+		{
+			name:             "not inside define or continued line",
+			makefile:         "define TPL\nfoo: bar\n\techo\nendef\nFILES = a \\\nb:c\nall:\n\techo a:b\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     7,
+			expectedMakefile: "define TPL\nfoo: bar\n\techo\nendef\nFILES = a \\\nb:c\n-include .bingo/Variables.mk\n\nall:\n\techo a:b\n",
+		},
+		{
+			name:             "before conditional with first rule",
+			makefile:         "GO ?= go\nifeq ($(CI),true)\n# ci runs in CI.\nci:\n\techo\nendif\nall:\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     2,
+			expectedMakefile: "GO ?= go\n-include .bingo/Variables.mk\n\nifeq ($(CI),true)\n# ci runs in CI.\nci:\n\techo\nendif\nall:\n",
+		},
+		{
+			name:             "no rules",
+			makefile:         "GO ?= go\n",
+			include:          ".bingo/Variables.mk",
+			expectedLine:     2,
+			expectedMakefile: "GO ?= go\n-include .bingo/Variables.mk\n",
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+
+			if tcase.makefile != "" {
+				testutil.Ok(t, os.WriteFile("Makefile", []byte(tcase.makefile), os.ModePerm))
+			}
+			for f, c := range tcase.other {
+				testutil.Ok(t, os.MkdirAll(filepath.Dir(f), os.ModePerm))
+				testutil.Ok(t, os.WriteFile(f, []byte(c), os.ModePerm))
+			}
+
+			line, err := initMakefile("Makefile", tcase.include)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expectedLine, line)
+
+			b, err := os.ReadFile("Makefile")
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expectedMakefile, string(b))
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/makefile"
+	"github.com/efficientgo/core/errors"
+)
+
+// makeBuiltinVariables are variables defined by GNU make itself, including the ones used by its implicit rules.
+var makeBuiltinVariables = []string{
+	"MAKE", "MAKEFLAGS", "MFLAGS", "MAKECMDGOALS", "MAKEFILE_LIST", "MAKEFILES", "MAKELEVEL", "MAKE_VERSION", "MAKE_HOST",
+	"MAKE_RESTARTS", "MAKE_TERMOUT", "MAKE_TERMERR", "MAKEOVERRIDES", "CURDIR", "SHELL", "VPATH", "GPATH", "SUFFIXES",
+	".DEFAULT_GOAL", ".RECIPEPREFIX", ".VARIABLES", ".FEATURES", ".INCLUDE_DIRS", ".SHELLFLAGS", ".SHELLSTATUS",
+	".EXTRA_PREREQS", ".LOADED", "OUTPUT_OPTION",
+	"AR", "AS", "CC", "CXX", "CPP", "FC", "M2C", "PC", "CO", "GET", "LD", "LEX", "YACC", "LINT", "MAKEINFO", "TEX",
+	"TEXI2DVI", "WEAVE", "CWEAVE", "TANGLE", "CTANGLE", "RM",
+	"ARFLAGS", "ASFLAGS", "CFLAGS", "CXXFLAGS", "COFLAGS", "CPPFLAGS", "FFLAGS", "GFLAGS", "LDFLAGS", "LDLIBS", "LFLAGS",
+	"YFLAGS", "PFLAGS", "RFLAGS", "LINTFLAGS",
+}
+
+// toolVariableName matches upper case variable names bingo generates for pinned tools (e.g. GOLANGCI_LINT), so only
+// variables that look like tools are reported as not pinned.
+var toolVariableName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// shellSeparators separate commands in a single recipe line.
+var shellSeparators = regexp.MustCompile(`&&|\|\||[;|]`)
+
+// defaultMakefile returns the makefile GNU make would use in the current directory, or "Makefile" if none exists.
+func defaultMakefile() string {
+	for _, f := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		if _, err := os.Stat(f); err == nil {
+			return f
+		}
+	}
+	return "Makefile"
+}
+
+// recipeCommands returns names of commands executed by the given recipe line (e.g. "golangci-lint" or "$(GOLANGCI_LINT)").
+func recipeCommands(recipe string) []string {
+	var cmds []string
+	for _, c := range shellSeparators.Split(recipe, -1) {
+		for _, f := range strings.Fields(strings.TrimLeft(c, "@-+( \t")) {
+			// Skip environment variables set for the command.
+			if strings.Contains(f, "=") && !strings.HasPrefix(f, "$") {
+				continue
+			}
+			cmds = append(cmds, f)
+			break
+		}
+	}
+	return cmds
+}
+
+// variableRef returns name of the variable, if the given word is a single variable reference (e.g. "$(GOLANGCI_LINT)").
+func variableRef(word string) (string, bool) {
+	refs := makefile.VariableRefs(word)
+	if len(refs) != 1 || (word != "$("+refs[0]+")" && word != "${"+refs[0]+"}") {
+		return "", false
+	}
+	return refs[0], true
+}
+
+func position(n makefile.Node) string {
+	return fmt.Sprintf("%v:%d", n.Pos().File, n.Pos().Line)
+}
+
+// lintMakefile returns issues found in the given makefile (and its includes) in regard to the pinned tools:
+//   - tools pinned, but never referenced via their variable (e.g. $(GOLANGCI_LINT)),
+//   - undefined upper case variables used as whole commands or prerequisites (e.g. $(MISSPELL)), which are likely tools
+//     that are not pinned; make builtins and variables set in the environment are not reported,
+//   - recipes calling pinned tools by their bare name (e.g. golangci-lint run), bypassing the pinned version.
+//
+// Nodes of the given helper file (Variables.mk generated by bingo) are not linted.
+func lintMakefile(mkFile, relModDir, helperFile string, pkgs []bingo.PackageRenderable) ([]string, error) {
+	nodes, err := makefile.ParseFileRecursive(mkFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %v", mkFile)
+	}
+
+	helperAbs, err := filepath.Abs(helperFile)
+	if err != nil {
+		return nil, err
+	}
+	isHelper := func(n makefile.Node) bool {
+		abs, err := filepath.Abs(n.Pos().File)
+		return err == nil && abs == helperAbs
+	}
+
+	var (
+		helperIncluded bool
+		defined        = map[string]struct{}{}
+	)
+	makefile.Walk(nodes, func(n makefile.Node) bool {
+		if isHelper(n) {
+			helperIncluded = true
+		}
+		if a, ok := n.(makefile.Assignment); ok {
+			defined[a.Name] = struct{}{}
+		}
+		return true
+	})
+
+	pinned := map[string]bingo.PackageRenderable{}
+	byName := map[string]bingo.PackageRenderable{}
+	for _, p := range pkgs {
+		pinned[p.EnvVarName] = p
+		byName[p.Name] = p
+		for _, v := range p.Versions {
+			byName[p.Name+"-"+v.Version] = p
+		}
+	}
+
+	var (
+		issues     []string
+		referenced = map[string]struct{}{}
+	)
+	checkUndefined := func(n makefile.Node, name, usage string) {
+		if _, ok := defined[name]; ok || slices.Contains(makeBuiltinVariables, name) {
+			return
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			return
+		}
+		if _, ok := pinned[name]; ok {
+			if !helperIncluded {
+				issues = append(issues, fmt.Sprintf("%v: $(%v) is pinned, but %v is not included; run 'bingo init'", position(n), name, helperFile))
+			}
+			return
+		}
+		if !toolVariableName.MatchString(name) {
+			return
+		}
+		issues = append(issues, fmt.Sprintf("%v: $(%v) is used as %v, but it's neither defined nor pinned; pin the tool with 'bingo get <package>'", position(n), name, usage))
+	}
+
+	makefile.Walk(nodes, func(n makefile.Node) bool {
+		if isHelper(n) {
+			return true
+		}
+		switch n := n.(type) {
+		case makefile.Assignment:
+			for _, r := range n.Refs {
+				referenced[r] = struct{}{}
+			}
+		case makefile.Conditional:
+			for _, r := range n.Refs {
+				referenced[r] = struct{}{}
+			}
+		case makefile.Rule:
+			for _, r := range n.Refs {
+				referenced[r] = struct{}{}
+			}
+			for _, p := range slices.Concat(n.Prereqs, n.OrderOnly) {
+				if name, ok := variableRef(p); ok {
+					checkUndefined(n, name, "prerequisite")
+				}
+			}
+			for _, rc := range n.Recipe {
+				for _, r := range rc.Refs {
+					referenced[r] = struct{}{}
+				}
+				for _, c := range recipeCommands(rc.Value) {
+					if name, ok := variableRef(c); ok {
+						checkUndefined(rc, name, "command")
+						continue
+					}
+					if p, ok := byName[filepath.Base(c)]; ok {
+						issues = append(issues, fmt.Sprintf("%v: %v is called directly, bypassing pinned version; use $(%v) instead", position(rc), c, p.EnvVarName))
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	for _, p := range pkgs {
+		if _, ok := referenced[p.EnvVarName]; ok {
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("%v: %v is pinned, but $(%v) is not referenced in %v or its includes", filepath.Join(relModDir, p.Versions[0].ModFile), p.Name, p.EnvVarName, mkFile))
+	}
+	return issues, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestLintMakefile(t *testing.T) {
+	pkgs := []bingo.PackageRenderable{
+		{Name: "faillint", EnvVarName: "FAILLINT", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}}},
+		{Name: "golangci-lint", EnvVarName: "GOLANGCI_LINT", Versions: []bingo.PackageVersionRenderable{{Version: "v1.55.0", ModFile: "golangci-lint.mod"}}},
+		{Name: "goimports", EnvVarName: "GOIMPORTS", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0", ModFile: "goimports.mod"}}},
+	}
+	variablesMk := `GO ?= go
+GOBIN ?= $(firstword $(subst :, ,${GOPATH}))/bin
+FAILLINT := $(GOBIN)/faillint-v1.5.0
+$(FAILLINT): $(BINGO_DIR)/faillint.mod
+	@cd $(BINGO_DIR) && $(GO) build -mod=mod -modfile=faillint.mod -o=$(GOBIN)/faillint-v1.5.0 "github.com/fatih/faillint"
+GOLANGCI_LINT := $(GOBIN)/golangci-lint-v1.55.0
+GOIMPORTS := $(GOBIN)/goimports-v0.1.0
+`
+
+	for _, tcase := range []struct {
+		name     string
+		makefile string
+
+		expectedIssues []string
+	}{
+		{
+			name: "all good",
+			makefile: `include .bingo/Variables.mk
+LINTERS := $(GOLANGCI_LINT) $(GOIMPORTS)
+
+lint: $(FAILLINT) $(LINTERS)
+	@$(FAILLINT) -paths "fmt.Println" ./... && $(MAKE) other
+	CGO_ENABLED=0 $(GOLANGCI_LINT) run
+`,
+		},
+		{
+			name: "unused, undeclared and bypassed tools",
+			makefile: `include .bingo/Variables.mk
+
+lint: $(FAILLINT) $(MISSPELL)
+	@$(FAILLINT) -paths "fmt.Println" ./...
+	$(MISSPELL) -w . | tee out; golangci-lint run
+	GOFLAGS=-mod=mod $(GOBIN)/goimports-v0.1.0 -w .
+`,
+			expectedIssues: []string{
+				"Makefile:3: $(MISSPELL) is used as prerequisite, but it's neither defined nor pinned; pin the tool with 'bingo get <package>'",
+				"Makefile:5: $(MISSPELL) is used as command, but it's neither defined nor pinned; pin the tool with 'bingo get <package>'",
+				"Makefile:5: golangci-lint is called directly, bypassing pinned version; use $(GOLANGCI_LINT) instead",
+				"Makefile:6: $(GOBIN)/goimports-v0.1.0 is called directly, bypassing pinned version; use $(GOIMPORTS) instead",
+				".bingo/golangci-lint.mod: golangci-lint is pinned, but $(GOLANGCI_LINT) is not referenced in Makefile or its includes",
+				".bingo/goimports.mod: goimports is pinned, but $(GOIMPORTS) is not referenced in Makefile or its includes",
+			},
+		},
+		{
+			name: "make builtins, environment and non tool variables",
+			makefile: `include .bingo/Variables.mk
+
+lint: $(FAILLINT) $(GOLANGCI_LINT) $(GOIMPORTS) $(OBJS_DIR)/main.o $(objs)
+	@$(FAILLINT) ./... && $(GOLANGCI_LINT) run && $(GOIMPORTS) -w .
+	$(CURDIR)/scripts/check.sh $(MAKEFILE_LIST) && $(MAKE) -C sub $(MAKECMDGOALS)
+	$(BINGO_LINT_TEST_TOOL) run && $(cmd) && $(.SHELLFLAGS)
+`,
+		},
+		{
+			name: "helper not included",
+			makefile: `lint:
+	@$(FAILLINT) ./... && $(GOLANGCI_LINT) run && $(GOIMPORTS) -w .
+`,
+			expectedIssues: []string{
+				"Makefile:2: $(FAILLINT) is pinned, but .bingo/Variables.mk is not included; run 'bingo init'",
+				"Makefile:2: $(GOLANGCI_LINT) is pinned, but .bingo/Variables.mk is not included; run 'bingo init'",
+				"Makefile:2: $(GOIMPORTS) is pinned, but .bingo/Variables.mk is not included; run 'bingo init'",
+			},
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Setenv("BINGO_LINT_TEST_TOOL", "echo")
+			t.Chdir(t.TempDir())
+			testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+			testutil.Ok(t, os.WriteFile(filepath.Join(".bingo", "Variables.mk"), []byte(variablesMk), os.ModePerm))
+			testutil.Ok(t, os.WriteFile("Makefile", []byte(tcase.makefile), os.ModePerm))
+
+			issues, err := lintMakefile("Makefile", ".bingo", filepath.Join(".bingo", "Variables.mk"), pkgs)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expectedIssues, issues)
+		})
+	}
+}
@@ -34,8 +34,17 @@ For detailed examples and documentation see: https://github.com/bwplotka/bingo
 	flags.StringVarP(&moddir, "moddir", "m", ".bingo", "Directory where separate modules for each binary will be maintained. \n"+
 		"Feel free to commit this directory to your VCS to bond binary versions to your project code. \n"+
 		"If the directory does not exist bingo logs and assumes a fresh project.")
+	cmd.AddCommand(NewBingoInitCommand(logger))
 	cmd.AddCommand(NewBingoGetCommand(logger))
+	cmd.AddCommand(NewBingoHelpTargetsCommand(logger))
 	cmd.AddCommand(NewBingoListCommand(logger))
+	cmd.AddCommand(NewBingoLintCommand(logger))
+	cmd.AddCommand(NewBingoRmCommand(logger))
+	cmd.AddCommand(NewBingoMvCommand(logger))
+	cmd.AddCommand(NewBingoGcCommand(logger))
+	cmd.AddCommand(NewBingoExportCommand(logger))
+	cmd.AddCommand(NewBingoHashCommand(logger))
+	cmd.AddCommand(NewBingoAuditCommand(logger))
 	cmd.AddCommand(NewBingoVersionCommand())
 	cmd.SetUsageTemplate(builtin.CommandHelpTemplate)
 	return cmd
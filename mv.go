@@ -0,0 +1,124 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/errors"
+)
+
+// move renames pinned tool from oldName to newName. It renames module, sum and binsum files and, if gobinDir is not empty,
+// already installed versioned binaries, so nothing has to be rebuilt. The <tool> link created by `bingo get -l`
+// is moved too, if it points to one of renamed binaries. Renamed binaries are updated in the manifest file, if not
+// empty, so `bingo gc` can still clean them.
+func move(logger *log.Logger, modDir string, gobinDir, manifestFile string, verbose bool, oldName, newName string) error {
+	if err := validateNewName([]string{""}, oldName, newName); err != nil {
+		return err
+	}
+	if err := validateTargetName(newName); err != nil {
+		return err
+	}
+
+	newExisting, err := existingModFiles(modDir, newName)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", newName)
+	}
+	if len(newExisting) > 0 {
+		return errors.Newf("found existing installed binaries %v under name you want to rename on. Remove target name %s or use different one", newExisting, newName)
+	}
+
+	existing, err := existingModFiles(modDir, oldName)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", oldName)
+	}
+	if len(existing) == 0 {
+		return errors.Newf("nothing to rename, tool %v not installed", oldName)
+	}
+
+	if gobinDir != "" {
+		bins, err := pinnedBinaries(oldName, existing)
+		if err != nil {
+			return err
+		}
+
+		oldBinPaths := make([]string, 0, len(bins))
+		for _, b := range bins {
+			oldBinPaths = append(oldBinPaths, filepath.Join(gobinDir, b))
+		}
+		link := filepath.Join(gobinDir, oldName)
+		linked, err := isLinkTo(link, oldBinPaths...)
+		if err != nil {
+			return errors.Wrapf(err, "check link %v", link)
+		}
+		var newLinkTarget string
+		if linked {
+			dst, err := os.Readlink(link)
+			if err != nil {
+				return err
+			}
+			newLinkTarget = filepath.Join(gobinDir, newName+strings.TrimPrefix(filepath.Base(dst), oldName))
+		}
+
+		renamed := map[string]string{}
+		for _, b := range oldBinPaths {
+			n := filepath.Join(gobinDir, newName+strings.TrimPrefix(filepath.Base(b), oldName))
+			if _, err := os.Stat(b); err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				if verbose {
+					logger.Println("binary", b, "not installed, nothing to rename; run 'bingo get", newName+"' to install it")
+				}
+				continue
+			}
+			if verbose {
+				logger.Println("renaming", b, "to", n)
+			}
+			if err := os.Rename(b, n); err != nil {
+				return errors.Wrap(err, "rename binary")
+			}
+			renamed[b] = n
+		}
+
+		if linked {
+			if err := os.RemoveAll(link); err != nil {
+				return errors.Wrap(err, "rm")
+			}
+			if err := os.Symlink(newLinkTarget, filepath.Join(gobinDir, newName)); err != nil {
+				return errors.Wrap(err, "symlink")
+			}
+		}
+
+		if manifestFile != "" && len(renamed) > 0 {
+			if err := bingo.UpdateManifest(manifestFile, func(bins []bingo.BuiltBinary) ([]bingo.BuiltBinary, error) {
+				for i, b := range bins {
+					if n, ok := renamed[b.Path]; ok {
+						bins[i].Path, bins[i].Name = n, newName
+					}
+				}
+				return bins, nil
+			}); err != nil {
+				return errors.Wrap(err, "update manifest")
+			}
+		}
+	}
+
+	for _, f := range existing {
+		for _, from := range []string{f, strings.TrimSuffix(f, ".mod") + ".sum", bingo.BinsumFilePath(f)} {
+			to := filepath.Join(modDir, newName+strings.TrimPrefix(filepath.Base(from), oldName))
+			if err := os.Rename(from, to); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return errors.Wrapf(err, "rename %v", from)
+			}
+		}
+	}
+	return nil
+}
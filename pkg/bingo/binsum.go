@@ -0,0 +1,100 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/efficientgo/core/errors"
+)
+
+// BinsumFilePath returns path of the binsum file for the given module file, e.g. faillint.binsum for faillint.mod.
+func BinsumFilePath(modFilePath string) string {
+	return strings.TrimSuffix(modFilePath, ".mod") + ".binsum"
+}
+
+// BinsumEntry is a sha256 digest of the binary built from the given version, for the given platform and Go version.
+// It's stored as a single, space separated line in the binsum file (e.g. "v1.5.0 linux/amd64 go1.25.3 <sha256>").
+type BinsumEntry struct {
+	Version string
+	// Platform is GOOS/GOARCH pair, e.g. linux/amd64.
+	Platform string
+	// GoVersion is a version reported by 'go env GOVERSION', e.g. go1.25.3.
+	GoVersion string
+	SHA256    string
+}
+
+func (e BinsumEntry) key() string {
+	return e.Version + " " + e.Platform + " " + e.GoVersion
+}
+
+func (e BinsumEntry) String() string {
+	return e.key() + " " + e.SHA256
+}
+
+// ReadBinsum returns all entries from the given binsum file. Not existing file is treated as empty one.
+func ReadBinsum(binsumFile string) ([]BinsumEntry, error) {
+	b, err := os.ReadFile(binsumFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []BinsumEntry
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 4 {
+			return nil, errors.Newf("%v:%d: malformed line %q; expected '<version> <GOOS>/<GOARCH> <go version> <sha256>'", binsumFile, i, line)
+		}
+		ret = append(ret, BinsumEntry{Version: f[0], Platform: f[1], GoVersion: f[2], SHA256: f[3]})
+	}
+	return ret, scanner.Err()
+}
+
+// writeBinsum writes given entries to the binsum file, sorted, so the file is deterministic.
+func writeBinsum(binsumFile string, entries []BinsumEntry) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key() < entries[j].key() })
+
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.String() + "\n")
+	}
+	return os.WriteFile(binsumFile, []byte(b.String()), 0666)
+}
+
+// VerifyOrRecordBinsum verifies the given entry against the one recorded in the binsum file for the same version,
+// platform and Go version. If there is none, the entry is recorded and entries of other versions are removed, as they
+// are no longer pinned. It returns true if the entry was recorded.
+func VerifyOrRecordBinsum(binsumFile string, e BinsumEntry) (recorded bool, _ error) {
+	entries, err := ReadBinsum(binsumFile)
+	if err != nil {
+		return false, err
+	}
+
+	kept := entries[:0]
+	for _, existing := range entries {
+		if existing.key() == e.key() {
+			if existing.SHA256 != e.SHA256 {
+				return false, errors.Newf("sha256 %v of the built binary does not match %v recorded in %v for %v; either the build is not reproducible "+
+					"or the recorded checksum (or binary) was tampered with", e.SHA256, existing.SHA256, binsumFile, e.key())
+			}
+			return false, nil
+		}
+		if existing.Version == e.Version {
+			kept = append(kept, existing)
+		}
+	}
+	return true, writeBinsum(binsumFile, append(kept, e))
+}
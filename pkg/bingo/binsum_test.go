@@ -0,0 +1,56 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestVerifyOrRecordBinsum(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "faillint.binsum")
+	testutil.Equals(t, f, BinsumFilePath(filepath.Join(filepath.Dir(f), "faillint.mod")))
+
+	e := BinsumEntry{Version: "v1.5.0", Platform: "linux/amd64", GoVersion: "go1.25.3", SHA256: "aaa"}
+	recorded, err := VerifyOrRecordBinsum(f, e)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, recorded)
+
+	// Same build.
+	recorded, err = VerifyOrRecordBinsum(f, e)
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, recorded)
+
+	// Other platforms and Go versions are recorded next to each other.
+	recorded, err = VerifyOrRecordBinsum(f, BinsumEntry{Version: "v1.5.0", Platform: "darwin/arm64", GoVersion: "go1.25.3", SHA256: "bbb"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, recorded)
+
+	b, err := os.ReadFile(f)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.5.0 darwin/arm64 go1.25.3 bbb\nv1.5.0 linux/amd64 go1.25.3 aaa\n", string(b))
+
+	// Not reproducible build or tampered binary.
+	e.SHA256 = "ccc"
+	_, err = VerifyOrRecordBinsum(f, e)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "sha256 ccc of the built binary does not match aaa recorded in "+f+" for v1.5.0 linux/amd64 go1.25.3; "+
+		"either the build is not reproducible or the recorded checksum (or binary) was tampered with", err.Error())
+
+	// New version replaces entries of the previous one.
+	recorded, err = VerifyOrRecordBinsum(f, BinsumEntry{Version: "v1.6.0", Platform: "linux/amd64", GoVersion: "go1.25.3", SHA256: "ddd"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, recorded)
+
+	entries, err := ReadBinsum(f)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []BinsumEntry{{Version: "v1.6.0", Platform: "linux/amd64", GoVersion: "go1.25.3", SHA256: "ddd"}}, entries)
+
+	testutil.Ok(t, os.WriteFile(f, []byte("v1.6.0 linux/amd64 ddd\n"), os.ModePerm))
+	_, err = ReadBinsum(f)
+	testutil.NotOk(t, err)
+}
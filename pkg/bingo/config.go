@@ -0,0 +1,213 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/efficientgo/core/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is a name of the optional, project level bingo configuration file kept in the mod directory.
+const ConfigFileName = "bingo.yaml"
+
+// allowedGoEnv is a set of Go environment variables that can be configured per project.
+var allowedGoEnv = map[string]struct{}{
+	"GOAUTH":     {},
+	"GOFLAGS":    {},
+	"GOINSECURE": {},
+	"GONOPROXY":  {},
+	"GONOSUMDB":  {},
+	"GOPRIVATE":  {},
+	"GOPROXY":    {},
+	"GOSUMDB":    {},
+}
+
+// Config is a project level bingo configuration. For example:
+//
+//	goEnv:
+//	  GOPRIVATE: gitlab.example.com/*
+//	  GOPROXY: https://proxy.golang.org,direct
+//	helpers: [env, taskfile, tools.sh.tmpl]
+//	reproducible: true
+type Config struct {
+	// GoEnv are Go environment variables (e.g. GOPRIVATE) that are set for every go command bingo runs and
+	// that are reproduced by generated helpers, so pinned tools can be installed without global Go environment changes.
+	GoEnv map[string]string `yaml:"goEnv,omitempty"`
+	// Helpers selects helpers generated in the mod directory. Each element is either a built-in helper name (mk, env,
+	// just, fish, ps1, envrc, taskfile or go) or a name of the Go text/template file with .tmpl suffix placed in the mod directory,
+	// rendered to the file with the same name without the suffix. If not specified, DefaultHelpers are generated.
+	Helpers []string `yaml:"helpers,omitempty"`
+	// GoToolsFile is a slash separated path of the Go file generated by "go" helper, relative to the mod directory.
+	// It has to be outside of the mod directory, which is a separate Go module. Its directory name is used as a package
+	// name. Defaults to DefaultGoToolsFile.
+	GoToolsFile string `yaml:"goToolsFile,omitempty"`
+	// Reproducible enables reproducible builds of pinned tools, so the same pin gives the same binary on every machine
+	// (for the same platform and Go version): ReproducibleBuildFlags are added, Go environment variables leaking from
+	// the user's shell (ReproducibleClearedEnv) are cleared and cgo is disabled, unless tool's build environment
+	// enables it. Enabled in the configuration created for new projects.
+	Reproducible bool `yaml:"reproducible,omitempty"`
+	// Binsum enables recording sha256 of each built binary per platform and Go version in the committed <tool>.binsum
+	// file and verifying binaries against it after each build and before shims or helpers use an existing binary.
+	// Requires Reproducible, otherwise binaries built on different machines would not match.
+	Binsum bool `yaml:"binsum,omitempty"`
+}
+
+// NewProjectConfig is the content of the configuration file created together with the mod directory.
+const NewProjectConfig = `# Project level bingo configuration. See https://github.com/bwplotka/bingo.
+# Build pinned tools reproducibly: with -trimpath and -buildvcs=false, without GOFLAGS (and similar) from the
+# environment and with cgo disabled, unless tool's build environment enables it.
+reproducible: true
+`
+
+// ReproducibleClearedEnv are Go environment variables cleared in reproducible mode, unless configured in GoEnv.
+var ReproducibleClearedEnv = []string{"GOAMD64", "GOEXPERIMENT", "GOFLAGS"}
+
+// ReproducibleEnv returns environment variables in KEY=VALUE form that clear ReproducibleClearedEnv, except the ones
+// configured in GoEnv. Nil if reproducible mode is disabled.
+func (c Config) ReproducibleEnv() []string {
+	if !c.Reproducible {
+		return nil
+	}
+	var ret []string
+	for _, e := range ReproducibleClearedEnv {
+		if _, ok := c.GoEnv[e]; !ok {
+			ret = append(ret, e+"=")
+		}
+	}
+	return ret
+}
+
+// DefaultHelpers are helpers generated when configuration does not specify any.
+var DefaultHelpers = []string{"mk", "env", "just"}
+
+// customHelperSuffix is a suffix of custom helper templates.
+const customHelperSuffix = ".tmpl"
+
+func isCustomHelper(h string) bool {
+	return strings.HasSuffix(h, customHelperSuffix)
+}
+
+// EnabledHelpers returns helpers that should be generated.
+func (c Config) EnabledHelpers() []string {
+	if c.Helpers == nil {
+		return DefaultHelpers
+	}
+	return c.Helpers
+}
+
+// HelperFiles returns names of all files in the mod directory that helpers depend on or generate.
+func (c Config) HelperFiles() []string {
+	var ret []string
+	for _, h := range c.EnabledHelpers() {
+		if isCustomHelper(h) {
+			ret = append(ret, h)
+		}
+		if f := c.HelperFileName(h); filepath.IsLocal(filepath.FromSlash(f)) {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+// LoadConfig loads project level configuration from the given mod directory. Missing file means empty configuration.
+func LoadConfig(modDir string) (Config, error) {
+	var cfg Config
+
+	f := filepath.Join(modDir, ConfigFileName)
+	b, err := os.ReadFile(f)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && err != io.EOF {
+		return cfg, errors.Wrapf(err, "parse %v", f)
+	}
+	if err := cfg.validate(); err != nil {
+		return cfg, errors.Wrapf(err, "validate %v", f)
+	}
+	return cfg, nil
+}
+
+func (c Config) validate() error {
+	if c.GoToolsFile != "" && (!strings.HasSuffix(c.GoToolsFile, ".go") || strings.HasSuffix(c.GoToolsFile, "_test.go")) {
+		return errors.Newf("goToolsFile: %v is not a Go source file", c.GoToolsFile)
+	}
+	if c.GoToolsFile != "" && filepath.IsLocal(filepath.FromSlash(c.GoToolsFile)) {
+		return errors.Newf("goToolsFile: %v is inside the mod directory, which is a separate Go module; place it in your module (e.g. %v), so it can be imported", c.GoToolsFile, DefaultGoToolsFile)
+	}
+	if c.Binsum && !c.Reproducible {
+		return errors.New("binsum: requires reproducible: true, otherwise binaries built on different machines do not match")
+	}
+	seen := map[string]struct{}{}
+	for _, h := range c.Helpers {
+		if _, ok := seen[h]; ok {
+			return errors.Newf("helpers: %v specified more than once", h)
+		}
+		seen[h] = struct{}{}
+
+		if _, ok := templatesByHelper[h]; ok {
+			continue
+		}
+		if !isCustomHelper(h) {
+			return errors.Newf("helpers: %v is neither built-in helper %v nor custom template file with %v suffix", h, BuiltinHelpers(), customHelperSuffix)
+		}
+		// Only files directly in mod directory, so .gitignore can include them.
+		if filepath.Base(h) != h {
+			return errors.Newf("helpers: custom template %v has to be placed directly in the mod directory", h)
+		}
+		if out := c.HelperFileName(h); out == "" || strings.HasSuffix(out, ".mod") || strings.HasSuffix(out, ".sum") ||
+			out == ConfigFileName || out == "README.md" || out == ".gitignore" {
+			return errors.Newf("helpers: custom template %v would overwrite bingo file %v", h, out)
+		}
+	}
+	for k := range c.GoEnv {
+		if _, ok := allowedGoEnv[k]; !ok {
+			allowed := make([]string, 0, len(allowedGoEnv))
+			for a := range allowedGoEnv {
+				allowed = append(allowed, a)
+			}
+			sort.Strings(allowed)
+			return errors.Newf("goEnv: %v is not supported; supported variables: %v", k, allowed)
+		}
+	}
+	return nil
+}
+
+// EnvVar is a single environment variable.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// GoEnvVars returns configured Go environment variables sorted by name.
+func (c Config) GoEnvVars() []EnvVar {
+	ret := make([]EnvVar, 0, len(c.GoEnv))
+	for k, v := range c.GoEnv {
+		ret = append(ret, EnvVar{Name: k, Value: v})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// GoEnvSlice returns configured Go environment variables in KEY=VALUE form.
+func (c Config) GoEnvSlice() envars.EnvSlice {
+	ret := make(envars.EnvSlice, 0, len(c.GoEnv))
+	for _, e := range c.GoEnvVars() {
+		ret = append(ret, e.Name+"="+e.Value)
+	}
+	return ret
+}
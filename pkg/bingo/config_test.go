@@ -0,0 +1,129 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("no config", func(t *testing.T) {
+		cfg, err := LoadConfig(t.TempDir())
+		testutil.Ok(t, err)
+		testutil.Equals(t, Config{}, cfg)
+		testutil.Equals(t, DefaultHelpers, cfg.EnabledHelpers())
+	})
+	t.Run("empty config", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), nil, os.ModePerm))
+
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, Config{}, cfg)
+	})
+	t.Run("go env", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`goEnv:
+  GOPRIVATE: gitlab.example.com/*
+  GOFLAGS: -mod=mod -tags=private
+`), os.ModePerm))
+
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, envars.EnvSlice{"GOFLAGS=-mod=mod -tags=private", "GOPRIVATE=gitlab.example.com/*"}, cfg.GoEnvSlice())
+	})
+	t.Run("new project config", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(NewProjectConfig), os.ModePerm))
+
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, Config{Reproducible: true}, cfg)
+		testutil.Equals(t, []string{"GOAMD64=", "GOEXPERIMENT=", "GOFLAGS="}, cfg.ReproducibleEnv())
+
+		cfg.GoEnv = map[string]string{"GOFLAGS": "-tags=private"}
+		testutil.Equals(t, []string{"GOAMD64=", "GOEXPERIMENT="}, cfg.ReproducibleEnv())
+		cfg.Reproducible = false
+		testutil.Equals(t, []string(nil), cfg.ReproducibleEnv())
+	})
+	t.Run("helpers", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`helpers: [env, taskfile, tools.sh.tmpl]
+`), os.ModePerm))
+
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{"env", "taskfile", "tools.sh.tmpl"}, cfg.EnabledHelpers())
+		testutil.Equals(t, []string{"variables.env", "Taskfile.yml", "tools.sh.tmpl", "tools.sh"}, cfg.HelperFiles())
+	})
+	t.Run("go helper", func(t *testing.T) {
+		// Files outside of mod directory are not part of it.
+		cfg := Config{Helpers: []string{"go"}}
+		testutil.Equals(t, []string(nil), cfg.HelperFiles())
+		testutil.Equals(t, "../internal/bingotools/tools.go", cfg.HelperFileName("go"))
+
+		cfg.GoToolsFile = "../internal/tools/tools.go"
+		testutil.Ok(t, cfg.validate())
+		testutil.Equals(t, "../internal/tools/tools.go", cfg.HelperFileName("go"))
+
+		testutil.NotOk(t, Config{GoToolsFile: "../tools/tools.txt"}.validate())
+		// Mod directory is a separate Go module, so package inside can't be imported.
+		testutil.NotOk(t, Config{GoToolsFile: "tools/tools.go"}.validate())
+	})
+	t.Run("no helpers", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`helpers: []
+`), os.ModePerm))
+
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, 0, len(cfg.EnabledHelpers()))
+	})
+	t.Run("wrong helpers", func(t *testing.T) {
+		for _, h := range []string{"[yaml]", "[env, env]", "[sub/tools.sh.tmpl]", "[x.mod.tmpl]", "[.tmpl]"} {
+			dir := t.TempDir()
+			testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("helpers: "+h+"\n"), os.ModePerm))
+
+			_, err := LoadConfig(dir)
+			testutil.NotOk(t, err, h)
+		}
+	})
+	t.Run("not supported go env", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`goEnv:
+  GOOS: windows
+`), os.ModePerm))
+
+		_, err := LoadConfig(dir)
+		testutil.NotOk(t, err)
+	})
+	t.Run("binsum without reproducible", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`binsum: true
+`), os.ModePerm))
+
+		_, err := LoadConfig(dir)
+		testutil.NotOk(t, err)
+
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`binsum: true
+reproducible: true
+`), os.ModePerm))
+		cfg, err := LoadConfig(dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, Config{Reproducible: true, Binsum: true}, cfg)
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		dir := t.TempDir()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(`goenv: {}
+`), os.ModePerm))
+
+		_, err := LoadConfig(dir)
+		testutil.NotOk(t, err)
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/efficientgo/core/errors"
+)
+
+// DockerfileToolsDir is a directory in the exported Dockerfile stage, where all pinned tools are installed.
+const DockerfileToolsDir = "/bingo/bin"
+
+// dockerfileTemplate is a multi-stage Dockerfile fragment installing all pinned tools. Each tool version is built in a
+// separate stage that depends only on its own module files, so its cache is invalidated only when its version changes
+// and tools are built in parallel. Built tools are copied to the final stage.
+const dockerfileTemplate = `# syntax=docker/dockerfile:1
+# Auto generated Dockerfile fragment managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# It installs all tools pinned in {{ .ModDir }} in {{ .ToolsDir }} directory of the "{{ .Stage }}" stage. Build context has to be
+# the project root. Copy tools to your image with:
+#
+#COPY --from={{ .Stage }} {{ .ToolsDir }}/ /usr/local/bin/
+#
+FROM {{ .BaseImage }} AS {{ .Stage }}-base
+WORKDIR /bingo
+ENV GOWORK=off{{ if .Reproducible }}{{ range .ReproducibleEnv }} {{ . }}{{ end }} CGO_ENABLED=0{{ end }}{{ range .GoEnv }} {{ .Name }}={{ shellQuote .Value }}{{ end }}
+# Required by go build, even though not accessed.
+COPY {{ .ModDir }}/go.mod ./
+{{- range $p := .MainPackages }}
+{{- range $v := $p.Versions }}
+
+FROM {{ $.Stage }}-base AS {{ stageName $p.Name $v.Version }}
+COPY {{ $.ModDir }}/{{ $v.ModFile }}{{ with sumFile $v.ModFile }} {{ $.ModDir }}/{{ . }}{{ end }} ./
+{{- with $p.EnvFile }}
+COPY {{ envFileSrc . }} {{ envFileDst . }}
+{{- end }}
+RUN --mount=type=cache,target=/root/.cache/go-build --mount=type=cache,target=/go/pkg/mod \
+	{{ with $p.EnvFile }}set -a && . "./{{ . }}" && set +a && {{ end }}{{ range $p.BuildEnvVarsFor $v.Version }}{{ shellQuoteEnv . }} {{ end }}go build {{ if $.Reproducible }}{{ range reproducibleBuildFlags ($p.BuildFlagsFor $v.Version) }}{{ . }} {{ end }}{{ end }}{{ range $p.BuildFlagsFor $v.Version }}{{ shellQuoteExpand . }} {{ end }}-mod=mod -modfile={{ $v.ModFile }} -o={{ $.ToolsDir }}/{{ $p.Name }}-{{ $v.Version }} "{{ $p.PackagePath }}"
+{{- if eq (len $p.Versions) 1 }} && \
+	ln -s {{ $p.Name }}-{{ $v.Version }} {{ $.ToolsDir }}/{{ $p.Name }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+FROM scratch AS {{ .Stage }}
+{{- range $p := .MainPackages }}
+{{- range $v := $p.Versions }}
+COPY --from={{ stageName $p.Name $v.Version }} {{ $.ToolsDir }}/ {{ $.ToolsDir }}/
+{{- end }}
+{{- end }}
+`
+
+var stageNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// DockerfileConfig configures exported Dockerfile fragment.
+type DockerfileConfig struct {
+	// BaseImage is an image with Go toolchain, used to build tools (e.g. golang:1.25).
+	BaseImage string
+	// Stage is a name of the build stage with installed tools.
+	Stage string
+}
+
+// ExportDockerfile writes multi-stage Dockerfile fragment installing given pinned tools from relModDir, which
+// has to be relative to the project root (Docker build context).
+func ExportDockerfile(w io.Writer, relModDir, version string, c DockerfileConfig, cfg Config, pkgs []PackageRenderable) error {
+	if !filepath.IsLocal(relModDir) {
+		return errors.Newf("mod directory %v has to be inside the project root, which is used as Docker build context", relModDir)
+	}
+
+	funcs := template.FuncMap{
+		// sumFile returns name of the sum file for given mod file or empty string, if it does not exist.
+		"sumFile": func(modFile string) (string, error) {
+			sumFile := strings.TrimSuffix(modFile, ".mod") + ".sum"
+			if _, err := os.Stat(filepath.Join(relModDir, sumFile)); err != nil {
+				if os.IsNotExist(err) {
+					return "", nil
+				}
+				return "", err
+			}
+			return sumFile, nil
+		},
+		// stageName returns name of the stage building given tool version.
+		"stageName": func(name, version string) string {
+			return strings.ToLower(stageNameRegexp.ReplaceAllString(c.Stage+"-"+name+"-"+version, "-"))
+		},
+		// envFileSrc returns path of the env file in the build context.
+		"envFileSrc": func(envFile string) (string, error) {
+			src := path.Join(filepath.ToSlash(relModDir), envFile)
+			if !filepath.IsLocal(filepath.FromSlash(src)) {
+				return "", errors.Newf("env file %v has to be inside the project root, which is used as Docker build context", envFile)
+			}
+			return src, nil
+		},
+		// envFileDst returns path of the env file in the stage, relative to the copied mod directory as in the project.
+		"envFileDst": func(envFile string) string {
+			return path.Join("/bingo", envFile)
+		},
+	}
+	t, err := template.New("Dockerfile").Funcs(templateFuncs).Funcs(funcs).Parse(dockerfileTemplate)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+
+	return t.Execute(w, struct {
+		Version      string
+		ModDir       string
+		ToolsDir     string
+		BaseImage    string
+		Stage        string
+		MainPackages []PackageRenderable
+		GoEnv        []EnvVar
+		// Reproducible and ReproducibleEnv are the same as in templateData.
+		Reproducible    bool
+		ReproducibleEnv []string
+	}{
+		Version:         version,
+		ModDir:          path.Clean(filepath.ToSlash(relModDir)),
+		ToolsDir:        DockerfileToolsDir,
+		BaseImage:       c.BaseImage,
+		Stage:           c.Stage,
+		MainPackages:    pkgs,
+		GoEnv:           cfg.GoEnvVars(),
+		Reproducible:    cfg.Reproducible,
+		ReproducibleEnv: cfg.ReproducibleEnv(),
+	})
+}
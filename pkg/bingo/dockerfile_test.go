@@ -0,0 +1,89 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestExportDockerfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(".bingo", "faillint.sum"), nil, os.ModePerm))
+
+	pkgs := []PackageRenderable{
+		{
+			Name:         "faillint",
+			PackagePath:  "github.com/fatih/faillint",
+			Versions:     []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+			BuildEnvVars: []string{"CGO_ENABLED=0"},
+			BuildFlags:   []string{"-tags=netgo"},
+			EnvFile:      "build.env",
+		},
+		{
+			// Each version is built in its own stage, named with allowed characters only.
+			Name:        "Thrift",
+			PackagePath: "github.com/apache/thrift/cmd/thrift",
+			Versions: []PackageVersionRenderable{
+				{Version: "v0.13.0+incompatible", ModFile: "thrift.mod"},
+				{Version: "v0.14.0", ModFile: "thrift.1.mod"},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	testutil.Ok(t, ExportDockerfile(&b, ".bingo", "v0.test", DockerfileConfig{BaseImage: "golang:1.25", Stage: "tools"}, Config{}, pkgs))
+	testutil.Equals(t, `# syntax=docker/dockerfile:1
+# Auto generated Dockerfile fragment managed by https://github.com/bwplotka/bingo v0.test. DO NOT EDIT.
+# It installs all tools pinned in .bingo in /bingo/bin directory of the "tools" stage. Build context has to be
+# the project root. Copy tools to your image with:
+#
+#COPY --from=tools /bingo/bin/ /usr/local/bin/
+#
+FROM golang:1.25 AS tools-base
+WORKDIR /bingo
+ENV GOWORK=off
+# Required by go build, even though not accessed.
+COPY .bingo/go.mod ./
+
+FROM tools-base AS tools-faillint-v1.5.0
+COPY .bingo/faillint.mod .bingo/faillint.sum ./
+COPY .bingo/build.env /bingo/build.env
+RUN --mount=type=cache,target=/root/.cache/go-build --mount=type=cache,target=/go/pkg/mod \
+	set -a && . "./build.env" && set +a && CGO_ENABLED=0 go build -tags=netgo -mod=mod -modfile=faillint.mod -o=/bingo/bin/faillint-v1.5.0 "github.com/fatih/faillint" && \
+	ln -s faillint-v1.5.0 /bingo/bin/faillint
+
+FROM tools-base AS tools-thrift-v0.13.0-incompatible
+COPY .bingo/thrift.mod ./
+RUN --mount=type=cache,target=/root/.cache/go-build --mount=type=cache,target=/go/pkg/mod \
+	go build -mod=mod -modfile=thrift.mod -o=/bingo/bin/Thrift-v0.13.0+incompatible "github.com/apache/thrift/cmd/thrift"
+
+FROM tools-base AS tools-thrift-v0.14.0
+COPY .bingo/thrift.1.mod ./
+RUN --mount=type=cache,target=/root/.cache/go-build --mount=type=cache,target=/go/pkg/mod \
+	go build -mod=mod -modfile=thrift.1.mod -o=/bingo/bin/Thrift-v0.14.0 "github.com/apache/thrift/cmd/thrift"
+
+FROM scratch AS tools
+COPY --from=tools-faillint-v1.5.0 /bingo/bin/ /bingo/bin/
+COPY --from=tools-thrift-v0.13.0-incompatible /bingo/bin/ /bingo/bin/
+COPY --from=tools-thrift-v0.14.0 /bingo/bin/ /bingo/bin/
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, ExportDockerfile(&b, ".bingo", "v0.test", DockerfileConfig{BaseImage: "golang:1.25", Stage: "tools"}, Config{Reproducible: true}, pkgs))
+	testutil.Assert(t, strings.Contains(b.String(), `ENV GOWORK=off GOAMD64= GOEXPERIMENT= GOFLAGS= CGO_ENABLED=0
+`), b.String())
+	testutil.Assert(t, strings.Contains(b.String(), `CGO_ENABLED=0 go build -trimpath -buildvcs=false -tags=netgo -mod=mod`), b.String())
+
+	testutil.NotOk(t, ExportDockerfile(&b, "../.bingo", "v0.test", DockerfileConfig{}, Config{}, pkgs))
+
+	pkgs[0].EnvFile = "../../build.env"
+	testutil.NotOk(t, ExportDockerfile(&b, ".bingo", "v0.test", DockerfileConfig{}, Config{}, pkgs))
+}
@@ -0,0 +1,103 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/mod"
+	"github.com/efficientgo/core/errcapture"
+	"github.com/efficientgo/core/errors"
+)
+
+// HashModDir returns deterministic, hex encoded SHA256 digest of all pinned tools: module and sum files in the mod
+// directory, project configuration (ConfigFileName), env files referenced by module files (see EnvFileCommand) and
+// given Go version (e.g. go1.25.3), if not empty. All of them change built binaries. Temporary files of in-progress
+// 'bingo get' and auto-generated files (helpers, fake root go.mod) are ignored. It's meant to be used as CI cache key.
+func HashModDir(modDir string, goVersion string) (string, error) {
+	var files []string
+	if _, err := os.Stat(filepath.Join(modDir, ConfigFileName)); err == nil {
+		files = append(files, ConfigFileName)
+	}
+	for _, glob := range []string{"*.mod", "*.sum"} {
+		matches, err := filepath.Glob(filepath.Join(modDir, glob))
+		if err != nil {
+			return "", err
+		}
+		for _, m := range matches {
+			base := filepath.Base(m)
+			if strings.Contains(base, ".tmp.") || base == FakeRootModFileName {
+				continue
+			}
+			files = append(files, base)
+
+			if !strings.HasSuffix(base, ".mod") {
+				continue
+			}
+			envFile, err := modEnvFile(m)
+			if err != nil {
+				return "", errors.Wrapf(err, "read %v", base)
+			}
+			if envFile != "" && !slices.Contains(files, envFile) {
+				files = append(files, envFile)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	if goVersion != "" {
+		_, _ = h.Write([]byte(goVersion + "\x00"))
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(modDir, filepath.FromSlash(f)))
+		if err != nil {
+			return "", err
+		}
+		// Names are hashed too, so renames and moved content between files are detected.
+		_, _ = h.Write([]byte(f + "\x00"))
+		_, _ = h.Write(b)
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// modEnvFile returns path of the env file declared in the given module file via EnvFileCommand, if any.
+func modEnvFile(modFile string) (_ string, err error) {
+	mf, err := mod.OpenFileForRead(modFile)
+	if err != nil {
+		return "", err
+	}
+	defer errcapture.Do(&err, mf.Close, "close")
+
+	for _, c := range mf.Comments() {
+		if strings.HasPrefix(c, EnvFileCommand+" ") {
+			return path.Clean(strings.TrimSpace(strings.TrimPrefix(c, EnvFileCommand))), nil
+		}
+	}
+	return "", nil
+}
+
+// HashFile returns hex encoded SHA256 digest of the given file (e.g. built binary).
+func HashFile(file string) (_ string, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
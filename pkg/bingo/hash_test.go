@@ -0,0 +1,85 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestHashModDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(f, content string) {
+		t.Helper()
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, f), []byte(content), os.ModePerm))
+	}
+	write("faillint.mod", "require github.com/fatih/faillint v1.5.0")
+	write("faillint.sum", "sum")
+
+	h, err := HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+
+	// Temporary and generated files do not change the hash.
+	write("faillint.tmp.mod", "tmp")
+	write(FakeRootModFileName, "module _")
+	write("Variables.mk", "X := 1")
+	h2, err := HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Equals(t, h, h2)
+
+	// Go version does.
+	h2, err = HashModDir(dir, "go1.24.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h != h2)
+
+	// Pinned version does.
+	write("faillint.mod", "require github.com/fatih/faillint v1.6.0")
+	h2, err = HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h != h2)
+	h = h2
+
+	// Configuration does.
+	write(ConfigFileName, "reproducible: true\n")
+	h2, err = HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h != h2)
+	h = h2
+
+	// Referenced env file does.
+	write("build.env", "CGO_ENABLED=0\n")
+	h2, err = HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Equals(t, h, h2)
+
+	write("faillint.mod", "// bingo:envfile build.env\n\nrequire github.com/fatih/faillint v1.6.0")
+	h, err = HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h != h2)
+
+	write("build.env", "CGO_ENABLED=1\n")
+	h2, err = HashModDir(dir, "go1.25.3")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h != h2)
+
+	// Missing env file is an error.
+	testutil.Ok(t, os.Remove(filepath.Join(dir, "build.env")))
+	_, err = HashModDir(dir, "go1.25.3")
+	testutil.NotOk(t, err)
+}
+
+func TestHashFile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "bin")
+	testutil.Ok(t, os.WriteFile(f, []byte("binary"), os.ModePerm))
+
+	h, err := HashFile(f)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "9a3a45d01531a20e89ac6ae10b0b0beb0492acd7216a368aa062d1a5fecaf9cd", h)
+
+	_, err = HashFile(f + "-not-existing")
+	testutil.NotOk(t, err)
+}
@@ -4,75 +4,401 @@
 package bingo
 
 import (
+	"bytes"
+	"go/format"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/efficientgo/core/errors"
 )
 
-// RemoveHelpers deletes helpers from mod directory.
-func RemoveHelpers(modDir string) error {
-	for ext := range templatesByFileExt {
-		v := "variables." + ext
-		if ext == "mk" {
-			// Exception: for backward compatibility.
-			v = "Variables.mk"
+// RemoveHelpers deletes all built-in helpers and custom helpers configured in given configuration from mod directory.
+func RemoveHelpers(modDir string, cfg Config) error {
+	for _, h := range BuiltinHelpers() {
+		if err := os.RemoveAll(filepath.Join(modDir, cfg.HelperFileName(h))); err != nil {
+			return err
+		}
+	}
+	for _, h := range cfg.EnabledHelpers() {
+		if !isCustomHelper(h) {
+			continue
 		}
-		if err := os.RemoveAll(filepath.Join(modDir, v)); err != nil {
+		if err := os.RemoveAll(filepath.Join(modDir, cfg.HelperFileName(h))); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// GenHelpers generates helpers to allows reliable binaries use. Regenerate if needed.
-// It is expected to have at least one mod file.
-// TODO(bwplotka): Allow installing those optionally?
-func GenHelpers(relModDir, version string, pkgs []PackageRenderable) error {
-	for ext, tmpl := range templatesByFileExt {
-		v := "variables." + ext
-		if ext == "mk" {
-			// Exception: for backward compatibility.
-			v = "Variables.mk"
+// BuiltinHelpers returns sorted names of all built-in helpers.
+func BuiltinHelpers() []string {
+	ret := make([]string, 0, len(templatesByHelper))
+	for h := range templatesByHelper {
+		ret = append(ret, h)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// DefaultGoToolsFile is a default path of the Go package file generated by "go" helper, relative to the mod directory.
+// It's outside of the mod directory, which is a separate Go module, so the package can be imported.
+const DefaultGoToolsFile = "../internal/bingotools/tools.go"
+
+// HelperFileName returns slash separated path of the file generated by given helper, relative to the mod directory.
+func (c Config) HelperFileName(helper string) string {
+	if isCustomHelper(helper) {
+		return strings.TrimSuffix(helper, customHelperSuffix)
+	}
+
+	switch helper {
+	case "mk":
+		// Exception: for backward compatibility.
+		return "Variables.mk"
+	case "just":
+		// Named after the tools, not variables, as it also provides recipes.
+		return "tools.just"
+	case "envrc":
+		// Not hidden, so it's not mistaken with the project's .envrc.
+		return "envrc"
+	case "taskfile":
+		// Name that go-task recognizes.
+		return "Taskfile.yml"
+	case "go":
+		if c.GoToolsFile != "" {
+			return c.GoToolsFile
+		}
+		return DefaultGoToolsFile
+	}
+	return "variables." + helper
+}
+
+// GenHelpers generates helpers enabled in given configuration to allows reliable binaries use. Regenerate if needed.
+// Disabled built-in helpers are removed. It is expected to have at least one mod file.
+func GenHelpers(relModDir, version string, cfg Config, pkgs []PackageRenderable) error {
+	toolsHash, err := HashModDir(relModDir, "")
+	if err != nil {
+		return errors.Wrap(err, "hash mod dir")
+	}
+	data := templateData{
+		Version:         version,
+		MainPackages:    pkgs,
+		Example:         examplePackage(pkgs),
+		GoEnv:           cfg.GoEnvVars(),
+		Reproducible:    cfg.Reproducible,
+		ReproducibleEnv: cfg.ReproducibleEnv(),
+		Binsum:          cfg.Binsum,
+		ToolsHash:       toolsHash,
+	}
+
+	enabled := map[string]struct{}{}
+	for _, h := range cfg.EnabledHelpers() {
+		enabled[h] = struct{}{}
+	}
+
+	for _, h := range BuiltinHelpers() {
+		f := cfg.HelperFileName(h)
+		if _, ok := enabled[h]; !ok {
+			// Remove in case it was enabled before.
+			if err := os.RemoveAll(filepath.Join(relModDir, f)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := genHelper(f, templatesByHelper[h], relModDir, data); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+
+	for _, h := range cfg.EnabledHelpers() {
+		if !isCustomHelper(h) {
+			continue
+		}
+		tmpl, err := os.ReadFile(filepath.Join(relModDir, h))
+		if err != nil {
+			return errors.Wrapf(err, "read custom helper template %v", h)
 		}
-		if err := genHelper(v, tmpl, relModDir, version, pkgs); err != nil {
-			return errors.Wrap(err, v)
+		f := cfg.HelperFileName(h)
+		if err := genHelper(f, string(tmpl), relModDir, data); err != nil {
+			return errors.Wrap(err, f)
 		}
 	}
 	return nil
 }
 
+// templateData is data passed to helper templates, including custom ones.
 type templateData struct {
 	Version      string
 	GobinPath    string
 	MainPackages []PackageRenderable
 	RelModDir    string
+	// GoEnv are project level Go environment variables from the bingo configuration file.
+	GoEnv []EnvVar
+	// Reproducible is true if pinned tools have to be built reproducibly. See Config.Reproducible.
+	Reproducible bool
+	// ReproducibleEnv are environment variables in KEY=VALUE form clearing Go environment leaking from the user's shell,
+	// set in reproducible mode.
+	ReproducibleEnv []string
+	// Binsum is true if built binaries have to be verified against checksums in binsum files. See Config.Binsum.
+	Binsum bool
+	// Example is a package used in usage examples. It's the first non array package, if any.
+	Example PackageRenderable
+	// ToolsHash is a digest of all pinned tools module, configuration and env files, without Go version. See HashModDir.
+	ToolsHash string
+	// GoPackage is a Go package name matching the directory of the generated file.
+	GoPackage string
+	// ModuleToModDir is a slash separated path of the mod directory, relative to the root of the Go module (directory
+	// with go.mod file) the generated file belongs to. If there is none, it's relative to the working directory.
+	ModuleToModDir string
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9_./:,=@%+-]+$`)
+
+var goNameSepRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+var justNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// shellQuote quotes given string for POSIX shell, if needed.
+func shellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var envRefRegexp = regexp.MustCompile(`^\$(\{[A-Za-z_][A-Za-z0-9_]*\}|[A-Za-z_][A-Za-z0-9_]*)`)
+
+// shellQuoteExpand quotes given build environment variable value or build flag for POSIX shell, if needed, keeping
+// environment variable references (e.g. "${HOME}") expanded by the shell. Any other shell syntax is escaped.
+func shellQuoteExpand(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '$':
+			if ref := envRefRegexp.FindString(s[i:]); ref != "" {
+				b.WriteString(ref)
+				i += len(ref) - 1
+				continue
+			}
+			b.WriteString(`\$`)
+		case '"', '\\', '`':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// shellQuoteEnv quotes value of given NAME=value build environment variable for POSIX shell, if needed, so it can be
+// used as a command prefix. Environment variable references in the value are expanded by the shell.
+func shellQuoteEnv(s string) string {
+	name, value, _ := strings.Cut(s, "=")
+	return name + "=" + shellQuoteExpand(value)
+}
+
+// shReproducibleEnv returns POSIX shell assignments of environment variables set for reproducible builds, ready to be
+// exported.
+func shReproducibleEnv(reproducibleEnv []string) string {
+	return strings.Join(append(append([]string{}, reproducibleEnv...), "CGO_ENABLED=0"), " ")
+}
+
+// shHostEnv returns POSIX shell assignments of the Go environment every build runs with: configured Go environment and
+// host platform, taken from the go command in GO variable.
+func shHostEnv(goEnv []EnvVar) string {
+	var b strings.Builder
+	b.WriteString("GOWORK=off ")
+	for _, e := range goEnv {
+		b.WriteString(e.Name + "=" + shellQuote(e.Value) + " ")
+	}
+	b.WriteString(`GOOS="$("${GO}" env GOHOSTOS)" GOARCH="$("${GO}" env GOHOSTARCH)" GOARM="$("${GO}" env GOHOSTARM)"`)
+	return b.String()
 }
 
-func genHelper(f, tmpl, relModDir, version string, pkgs []PackageRenderable) error {
-	t, err := template.New(f).Parse(tmpl)
+// shBuildCommand returns POSIX shell command building given version of the pinned tool the same way as Variables.mk
+// does. It has to be run in the mod directory. Go command and path of the built binary are taken from GO and BINGO_BIN
+// variables.
+func shBuildCommand(p PackageRenderable, v PackageVersionRenderable, goEnv []EnvVar, reproducible bool, reproducibleEnv []string) string {
+	var b strings.Builder
+	if reproducible {
+		b.WriteString("export " + shReproducibleEnv(reproducibleEnv) + " && ")
+	}
+	if p.EnvFile != "" {
+		b.WriteString("set -a && . " + shellQuote("./"+p.EnvFile) + " && set +a && ")
+	}
+	b.WriteString(shHostEnv(goEnv) + " ")
+	for _, e := range p.BuildEnvVarsFor(v.Version) {
+		b.WriteString(shellQuoteEnv(e) + " ")
+	}
+	b.WriteString(`"${GO}" build `)
+	flags := p.BuildFlagsFor(v.Version)
+	if reproducible {
+		for _, f := range reproducibleBuildFlags(flags) {
+			b.WriteString(f + " ")
+		}
+	}
+	for _, f := range flags {
+		b.WriteString(shellQuoteExpand(f) + " ")
+	}
+	b.WriteString(`-mod=mod -modfile=` + shellQuote(v.ModFile) + ` -o="${BINGO_BIN}" ` + shellQuote(p.PackagePath))
+	return b.String()
+}
+
+// ShBuildCommand returns POSIX shell command building given version of the pinned tool. See shBuildCommand.
+func (d templateData) ShBuildCommand(p PackageRenderable, v PackageVersionRenderable) string {
+	return shBuildCommand(p, v, d.GoEnv, d.Reproducible, d.ReproducibleEnv)
+}
+
+var templateFuncs = template.FuncMap{
+	"shellQuote":       shellQuote,
+	"shellQuoteExpand": shellQuoteExpand,
+	"shellQuoteEnv":    shellQuoteEnv,
+	// fishQuote quotes given string for fish shell.
+	"fishQuote": func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	},
+	// psQuote quotes given string for PowerShell.
+	"psQuote": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	},
+	// makeEscape escapes given string, so Make does not expand it.
+	"makeEscape": func(s string) string {
+		return strings.ReplaceAll(s, "$", "$$")
+	},
+	// justEscape escapes given string, so just does not interpolate it.
+	"justEscape": func(s string) string {
+		return strings.ReplaceAll(s, "{{", "{{{{")
+	},
+	// taskEscape escapes given string, so go-task does not interpret it as a template.
+	"taskEscape": func(s string) string {
+		return strings.ReplaceAll(s, "{{", `{{"{{"}}`)
+	},
+	// reproducibleBuildFlags returns ReproducibleBuildFlags not specified in given build flags.
+	"reproducibleBuildFlags": reproducibleBuildFlags,
+	// binsumFile returns name of the binsum file for given module file.
+	"binsumFile": BinsumFilePath,
+	// goName returns given tool name as exported Go identifier, e.g. GolangciLint for golangci-lint.
+	"goName": goName,
+	// goRawString returns given string as Go raw string literal or, if it contains backquote, interpreted one.
+	"goRawString": func(s string) string {
+		if strings.Contains(s, "`") {
+			return strconv.Quote(s)
+		}
+		return "`" + s + "`"
+	},
+	// join joins given strings with the separator.
+	"join": func(s []string, sep string) string {
+		return strings.Join(s, sep)
+	},
+	// justName returns given name with characters not allowed in just recipe names replaced by '-'.
+	"justName": func(s string) string {
+		return justNameRegexp.ReplaceAllString(s, "-")
+	},
+}
+
+func examplePackage(pkgs []PackageRenderable) PackageRenderable {
+	for _, p := range pkgs {
+		if len(p.Versions) == 1 {
+			return p
+		}
+	}
+	if len(pkgs) == 0 {
+		return PackageRenderable{}
+	}
+	return pkgs[0]
+}
+
+func goName(s string) string {
+	var b strings.Builder
+	for _, w := range goNameSepRegexp.Split(s, -1) {
+		if w == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "Tool" + b.String()
+	}
+	return b.String()
+}
+
+// goPackageName returns Go package name for given directory.
+func goPackageName(dir string) string {
+	name := strings.ToLower(goNameSepRegexp.ReplaceAllString(filepath.Base(dir), ""))
+	if name == "" || name == "." || unicode.IsDigit(rune(name[0])) {
+		return "tools"
+	}
+	return name
+}
+
+// moduleToModDir returns slash separated path of the mod directory, relative to the root of the Go module the given
+// directory belongs to or, if there is none, relative to the working directory.
+func moduleToModDir(dir, relModDir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absModDir, err := filepath.Abs(relModDir)
+	if err != nil {
+		return "", err
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for d := absDir; ; d = filepath.Dir(d) {
+		// Mod directory is a separate module, so its go.mod does not count.
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil && d != absModDir {
+			root = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	rel, err := filepath.Rel(root, absModDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func genHelper(f, tmpl, relModDir string, data templateData) (err error) {
+	t, err := template.New(f).Funcs(templateFuncs).Parse(tmpl)
 	if err != nil {
 		return errors.Wrap(err, "parse template")
 	}
 
-	data := templateData{
-		Version:      version,
-		MainPackages: pkgs,
+	out := filepath.Join(relModDir, filepath.FromSlash(f))
+	data.GoPackage = goPackageName(filepath.Dir(out))
+	if data.ModuleToModDir, err = moduleToModDir(filepath.Dir(out), relModDir); err != nil {
+		return err
 	}
 
-	fb, err := os.Create(filepath.Join(relModDir, f))
-	if err != nil {
-		return errors.Wrap(err, "create")
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return err
 	}
-	defer func() {
-		if cerr := fb.Close(); cerr != nil {
-			if err != nil {
-				err = errors.Wrapf(err, "additionally error on close: %v", cerr)
-				return
-			}
-			err = cerr
+	content := b.Bytes()
+	if strings.HasSuffix(f, ".go") {
+		if content, err = format.Source(content); err != nil {
+			return errors.Wrap(err, "format generated Go code")
 		}
-	}()
-	return t.Execute(fb, data)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create dir")
+	}
+	return os.WriteFile(out, content, 0666)
 }
@@ -0,0 +1,231 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestGoName(t *testing.T) {
+	for _, tcase := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "faillint", expected: "Faillint"},
+		{name: "golangci-lint", expected: "GolangciLint"},
+		{name: "protoc-gen-go.v2", expected: "ProtocGenGoV2"},
+		{name: "go_bindata", expected: "GoBindata"},
+		{name: "2to3", expected: "Tool2to3"},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			testutil.Equals(t, tcase.expected, goName(tcase.name))
+		})
+	}
+}
+
+func TestShellQuoteExpand(t *testing.T) {
+	t.Setenv("HOME", "/home/bingo")
+
+	for _, tcase := range []struct {
+		s, expected, expectedShell string
+	}{
+		{s: "-tags=netgo", expected: "-tags=netgo", expectedShell: "-tags=netgo"},
+		{s: "${HOME}/go", expected: `"${HOME}/go"`, expectedShell: "/home/bingo/go"},
+		{s: "$HOME/go mod", expected: `"$HOME/go mod"`, expectedShell: "/home/bingo/go mod"},
+		{s: `-X 'main.v=$(whoami)' "\`, expected: `"-X 'main.v=\$(whoami)' \"\\"`, expectedShell: `-X 'main.v=$(whoami)' "\`},
+		{s: "$5 ${", expected: `"\$5 \${"`, expectedShell: "$5 ${"},
+	} {
+		t.Run(tcase.s, func(t *testing.T) {
+			testutil.Equals(t, tcase.expected, shellQuoteExpand(tcase.s))
+
+			out, err := exec.Command("sh", "-c", "printf %s "+tcase.expected).Output()
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expectedShell, string(out))
+		})
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files of helpers")
+
+func TestGenHelpers_Golden(t *testing.T) {
+	dir := t.TempDir()
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/project\n"), os.ModePerm))
+	modDir := filepath.Join(dir, ".bingo")
+	testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, FakeRootModFileName), []byte("module _\n"), os.ModePerm))
+	for _, f := range []string{"arr.mod", "arr.1.mod", "faillint.mod", "faillint.sum"} {
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, f), []byte("// "+f+"\n"), os.ModePerm))
+	}
+
+	// Array tool goes first, so examples have to pick the next one.
+	pkgs := []PackageRenderable{
+		{
+			Name:        "arr",
+			ModPath:     "github.com/example/arr",
+			PackagePath: "github.com/example/arr/cmd/arr",
+			EnvVarName:  "ARR_ARRAY",
+			Versions: []PackageVersionRenderable{
+				{Version: "v1.0.0", ModFile: "arr.mod"},
+				{Version: "v2.0.0", ModFile: "arr.1.mod"},
+			},
+		},
+		{
+			Name:         "faillint",
+			ModPath:      "github.com/fatih/faillint",
+			PackagePath:  "github.com/fatih/faillint",
+			EnvVarName:   "FAILLINT",
+			Versions:     []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+			BuildEnvVars: []string{"CGO_ENABLED=0", "GOMODCACHE=${HOME}/go mod"},
+			BuildFlags:   []string{"-tags=netgo", "-trimpath", "-ldflags=-X main.version=${VERSION} -X 'main.home=${HOME}'"},
+			EnvFile:      "build.env",
+		},
+	}
+	cfg := Config{
+		GoEnv:        map[string]string{"GOPRIVATE": "gitlab.example.com/*"},
+		Helpers:      BuiltinHelpers(),
+		Reproducible: true,
+		Binsum:       true,
+	}
+	testutil.Ok(t, GenHelpers(modDir, "v0.test", cfg, pkgs))
+
+	for _, h := range cfg.EnabledHelpers() {
+		f := cfg.HelperFileName(h)
+		t.Run(f, func(t *testing.T) {
+			got, err := os.ReadFile(filepath.Join(modDir, filepath.FromSlash(f)))
+			testutil.Ok(t, err)
+
+			// Go helper is generated outside of the mod directory.
+			golden := filepath.Join("testdata", "helpers", filepath.FromSlash(strings.TrimPrefix(f, "../")))
+			if *update {
+				testutil.Ok(t, os.MkdirAll(filepath.Dir(golden), os.ModePerm))
+				testutil.Ok(t, os.WriteFile(golden, got, 0666))
+			}
+			exp, err := os.ReadFile(golden)
+			testutil.Ok(t, err)
+			testutil.Equals(t, string(exp), string(got))
+		})
+	}
+}
+
+func TestGenHelpers_Just(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "protoc-gen-go.v2.mod"), nil, os.ModePerm))
+
+	pkgs := []PackageRenderable{{
+		Name:        "protoc-gen-go.v2",
+		ModPath:     "google.golang.org/protobuf",
+		PackagePath: "google.golang.org/protobuf/cmd/protoc-gen-go",
+		EnvVarName:  "PROTOC_GEN_GO_V2",
+		Versions:    []PackageVersionRenderable{{Version: "v1.36.0", ModFile: "protoc-gen-go.v2.mod"}},
+		BuildFlags:  []string{"-ldflags=-X main.tmpl={{x}}"},
+	}}
+	cfg := Config{Helpers: []string{"just"}}
+	testutil.Equals(t, []string{"tools.just"}, cfg.HelperFiles())
+	testutil.Ok(t, GenHelpers(modDir, "v0.test", cfg, pkgs))
+
+	b, err := os.ReadFile(filepath.Join(modDir, "tools.just"))
+	testutil.Ok(t, err)
+	got := string(b)
+
+	// Dots are not allowed in recipe names.
+	testutil.Assert(t, strings.Contains(got, "\ninstall-protoc-gen-go-v2:\n"), got)
+	testutil.Assert(t, strings.Contains(got, `PROTOC_GEN_GO_V2 := BINGO_GOBIN + "/protoc-gen-go.v2-v1.36.0"`), got)
+	// Just must not interpolate build flags.
+	testutil.Assert(t, strings.Contains(got, "main.tmpl={{{{x}}"), got)
+	testutil.Assert(t, !strings.Contains(got, "main.tmpl={{x}}"), got)
+
+	testutil.Ok(t, RemoveHelpers(modDir, cfg))
+	_, err = os.Stat(filepath.Join(modDir, "tools.just"))
+	testutil.Assert(t, os.IsNotExist(err))
+}
+
+func TestGenHelpers_GoEnsure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Ensure requires POSIX shell")
+	}
+
+	dir := t.TempDir()
+	gobin := filepath.Join(dir, "gobin")
+	testutil.Ok(t, os.MkdirAll(gobin, os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, "go"), []byte(fakeGo), 0755))
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/project\n\ngo 1.21\n"), os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(filepath.Join(dir, ".bingo"), os.ModePerm))
+	for f, content := range map[string]string{
+		FakeRootModFileName: "module _\n",
+		"tool.mod":          strings.ReplaceAll(shimTestModFile, "%s", "v1.2.3"),
+		"build.env":         "FROM_ENV_FILE=yes\n",
+	} {
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, ".bingo", f), []byte(content), os.ModePerm))
+	}
+	testutil.Ok(t, os.MkdirAll(filepath.Join(dir, "cmd", "ensure"), os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, "cmd", "ensure", "main.go"), []byte(`package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"example.com/project/internal/bingotools"
+)
+
+func main() {
+	if err := bingotools.Ensure(context.Background()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(bingotools.Tool())
+}
+`), os.ModePerm))
+
+	pkgs := []PackageRenderable{{
+		Name:         "tool",
+		ModPath:      "github.com/example/tool",
+		PackagePath:  "github.com/example/tool/cmd/tool",
+		EnvVarName:   "TOOL",
+		Versions:     []PackageVersionRenderable{{Version: "v1.2.3", ModFile: "tool.mod"}},
+		BuildEnvVars: []string{"CGO_ENABLED=1"},
+		BuildFlags:   []string{"-ldflags=-X main.home=${HOME}"},
+		EnvFile:      "build.env",
+	}}
+	t.Chdir(dir)
+
+	ensure := func(t *testing.T) (string, error) {
+		t.Helper()
+
+		// Mod directory is found from any directory of the module.
+		cmd := exec.Command("go", "run", ".")
+		cmd.Dir = filepath.Join(dir, "cmd", "ensure")
+		cmd.Env = append(os.Environ(), "GOBIN="+gobin, "GO="+filepath.Join(dir, "go"), "HOME=/home/bingo", "GOWORK=off", "GOTOOLCHAIN=local", "GOFLAGS=")
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	testutil.Ok(t, GenHelpers(".bingo", "v0.test", Config{Helpers: []string{"go"}, Reproducible: true}, pkgs))
+	out, err := ensure(t)
+	testutil.Ok(t, err, out)
+	testutil.Equals(t, "(re)installing "+gobin+"/tool-v1.2.3\n"+gobin+"/tool-v1.2.3\n", out)
+
+	b, err := exec.Command(filepath.Join(gobin, "tool-v1.2.3")).CombinedOutput()
+	testutil.Ok(t, err, string(b))
+	testutil.Equals(t, "built: build -trimpath -buildvcs=false -ldflags=-X main.home=/home/bingo -mod=mod -modfile=tool.mod -o="+gobin+"/tool-v1.2.3 github.com/example/tool/cmd/tool "+
+		"CGO_ENABLED=1 GOFLAGS= FROM_ENV_FILE=yes GOOS=testos \n", string(b))
+
+	// Existing binary not matching recorded checksum is rebuilt and verified.
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, ".bingo", "tool.binsum"), []byte("v1.2.3 testos/testarch go1.test 0000\n"), os.ModePerm))
+	testutil.Ok(t, GenHelpers(".bingo", "v0.test", Config{Helpers: []string{"go"}, Reproducible: true, Binsum: true}, pkgs))
+	out, err = ensure(t)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "sha256 of existing "+gobin+"/tool-v1.2.3 does not match the one recorded in tool.binsum; reinstalling\n"+
+		"(re)installing "+gobin+"/tool-v1.2.3\n"+
+		"sha256 of "+gobin+"/tool-v1.2.3 does not match the one recorded in tool.binsum; either the build is not reproducible or the checksum (or binary) was tampered with\n"+
+		"exit status 1\n", out)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/efficientgo/core/errcapture"
+	"github.com/efficientgo/core/errors"
+)
+
+// BuiltBinary is a manifest entry describing a single binary built by bingo.
+type BuiltBinary struct {
+	// Path is an absolute path to the built binary, e.g. $GOBIN/<name>-<version>.
+	Path    string    `json:"path"`
+	Name    string    `json:"name"`
+	Version string    `json:"version"`
+	Package string    `json:"package"`
+	BuiltAt time.Time `json:"builtAt"`
+	// SHA256 is a hex encoded digest of the binary right after the build, so builds can be compared across machines.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// DefaultManifestFile returns path of the manifest file that records all binaries built by bingo on this machine.
+func DefaultManifestFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bingo", "binaries.jsonl"), nil
+}
+
+// RecordBuiltBinary appends given entry to the manifest file, creating it if needed.
+// Entries are stored one JSON object per line. The manifest is locked while appending, so entries are not lost when
+// other bingo invocation rewrites it at the same time (see UpdateManifest).
+func RecordBuiltBinary(manifestFile string, b BuiltBinary) (err error) {
+	if err := os.MkdirAll(filepath.Dir(manifestFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, unlock, "unlock")
+
+	f, err := os.OpenFile(manifestFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// ReadManifest returns all entries from the manifest file, deduplicated by path (the latest entry wins) and in order
+// of the first appearance. Not existing manifest is treated as empty one.
+func ReadManifest(manifestFile string) (_ []BuiltBinary, err error) {
+	f, err := os.Open(manifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	var (
+		ret   []BuiltBinary
+		index = map[string]int{}
+	)
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var b BuiltBinary
+		if err := json.Unmarshal(scanner.Bytes(), &b); err != nil {
+			return nil, errors.Wrapf(err, "%v:%d: parse", manifestFile, i)
+		}
+		if j, ok := index[b.Path]; ok {
+			ret[j] = b
+			continue
+		}
+		index[b.Path] = len(ret)
+		ret = append(ret, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+const (
+	// manifestLockStaleAge is an age after which the manifest lock is assumed to be left by a killed process.
+	manifestLockStaleAge = 1 * time.Minute
+	manifestLockTimeout  = 2 * manifestLockStaleAge
+)
+
+// lockManifest acquires exclusive lock of the manifest file and returns function releasing it. The lock is a separate
+// file, created exclusively, since the manifest itself is atomically replaced on writes.
+func lockManifest(manifestFile string) (unlock func() error, _ error) {
+	lockFile := manifestFile + ".lock"
+	for deadline := time.Now().Add(manifestLockTimeout); ; {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+		if err == nil {
+			if err := f.Close(); err != nil {
+				return nil, err
+			}
+			return func() error { return os.Remove(lockFile) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, "lock manifest")
+		}
+		if fi, err := os.Stat(lockFile); err == nil && time.Since(fi.ModTime()) > manifestLockStaleAge {
+			_ = os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Newf("timed out waiting for manifest lock %v; remove it, if no other bingo is running", lockFile)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// UpdateManifest replaces entries of the manifest file with ones returned by the given function, called with the current
+// entries. The manifest is locked in the meantime, so entries recorded concurrently are not lost.
+func UpdateManifest(manifestFile string, update func([]BuiltBinary) ([]BuiltBinary, error)) (err error) {
+	if err := os.MkdirAll(filepath.Dir(manifestFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	unlock, err := lockManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, unlock, "unlock")
+
+	bins, err := ReadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	bins, err = update(bins)
+	if err != nil {
+		return err
+	}
+	return writeManifest(manifestFile, bins)
+}
+
+// writeManifest atomically replaces the manifest file with given entries.
+func writeManifest(manifestFile string, bins []BuiltBinary) error {
+	var b bytes.Buffer
+	for _, bin := range bins {
+		line, err := json.Marshal(bin)
+		if err != nil {
+			return err
+		}
+		b.Write(append(line, '\n'))
+	}
+
+	tmp := manifestFile + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, manifestFile)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+func TestUpdateManifest_Concurrent(t *testing.T) {
+	manifestFile := filepath.Join(t.TempDir(), "binaries.jsonl")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			testutil.Ok(t, RecordBuiltBinary(manifestFile, BuiltBinary{Path: fmt.Sprintf("/gobin/tool-v1.0.%d", i), Name: "tool"}))
+		}(i)
+		// Rewrites keeping all entries must not lose entries appended concurrently.
+		go func() {
+			defer wg.Done()
+			testutil.Ok(t, UpdateManifest(manifestFile, func(bins []BuiltBinary) ([]BuiltBinary, error) {
+				time.Sleep(time.Millisecond)
+				return bins, nil
+			}))
+		}()
+	}
+	wg.Wait()
+
+	bins, err := ReadManifest(manifestFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 20, len(bins))
+
+	_, err = os.Stat(manifestFile + ".lock")
+	testutil.Assert(t, os.IsNotExist(err), "lock was not released")
+}
+
+func TestUpdateManifest_StaleLock(t *testing.T) {
+	manifestFile := filepath.Join(t.TempDir(), "binaries.jsonl")
+	lockFile := manifestFile + ".lock"
+
+	// Lock left by a killed process.
+	testutil.Ok(t, os.WriteFile(lockFile, nil, os.ModePerm))
+	old := time.Now().Add(-2 * manifestLockStaleAge)
+	testutil.Ok(t, os.Chtimes(lockFile, old, old))
+
+	testutil.Ok(t, RecordBuiltBinary(manifestFile, BuiltBinary{Path: "/gobin/tool-v1.0.0", Name: "tool"}))
+	bins, err := ReadManifest(manifestFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []BuiltBinary{{Path: "/gobin/tool-v1.0.0", Name: "tool"}}, bins)
+}
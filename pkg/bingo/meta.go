@@ -0,0 +1,224 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/efficientgo/core/errors"
+	"golang.org/x/mod/module"
+)
+
+// Placeholders expanded in build environment variables and flags (e.g. "-ldflags=-X main.version=${VERSION}").
+const (
+	// VersionPlaceholder is expanded to the version of the module (e.g. v1.2.3).
+	VersionPlaceholder = "VERSION"
+	// ModulePlaceholder is expanded to the module path.
+	ModulePlaceholder = "MODULE"
+	// CommitPlaceholder is expanded to the abbreviated commit hash for pseudo-versions, otherwise to empty string.
+	CommitPlaceholder = "COMMIT"
+)
+
+// splitMeta splits require comment metadata into tokens around spaces. Like in shell, single and double quotes group
+// characters (including spaces) into a single token and are removed, and backslash escapes the next character outside
+// of single quotes. Unterminated quote spans until the end of the line.
+func splitMeta(line string) []string {
+	var (
+		tokens []string
+		tok    strings.Builder
+		inTok  bool
+		quote  byte
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+				continue
+			}
+		case c == '\\' && i+1 < len(line) && (quote == 0 || line[i+1] == '"' || line[i+1] == '\\'):
+			i++
+			c = line[i]
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+				continue
+			}
+		case c == '\'' || c == '"':
+			quote, inTok = c, true
+			continue
+		case c == ' ' || c == '\t':
+			if inTok {
+				tokens = append(tokens, tok.String())
+				tok.Reset()
+				inTok = false
+			}
+			continue
+		}
+		tok.WriteByte(c)
+		inTok = true
+	}
+	if inTok {
+		tokens = append(tokens, tok.String())
+	}
+	return tokens
+}
+
+var metaSafeRegexp = regexp.MustCompile(`^[^\s'"\\]*$`)
+
+// quoteMeta returns the token in the form splitMeta parses back to the same token. Values of "KEY=value" and
+// "-flag=value" tokens are quoted, if needed, so the comment stays readable (e.g. -ldflags="-X main.version=v1.0.0").
+func quoteMeta(tok string) string {
+	if tok != "" && metaSafeRegexp.MatchString(tok) {
+		return tok
+	}
+	var prefix string
+	if i := strings.Index(tok, "="); i >= 0 && metaSafeRegexp.MatchString(tok[:i]) {
+		prefix, tok = tok[:i+1], tok[i+1:]
+	}
+	return prefix + `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(tok) + `"`
+}
+
+// joinMeta joins tokens into require comment metadata, quoting them if needed.
+func joinMeta(tokens []string) string {
+	quoted := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		quoted = append(quoted, quoteMeta(t))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// expandPlaceholders expands VersionPlaceholder, ModulePlaceholder and CommitPlaceholder references (e.g. "${VERSION}")
+// in s for the given module. Other references are expanded with lookupEnv if not nil, otherwise they are kept.
+func expandPlaceholders(s string, m module.Version, lookupEnv func(string) string) string {
+	return os.Expand(s, func(name string) string {
+		switch name {
+		case VersionPlaceholder:
+			return m.Version
+		case ModulePlaceholder:
+			return m.Path
+		case CommitPlaceholder:
+			if !module.IsPseudoVersion(m.Version) {
+				return ""
+			}
+			rev, _ := module.PseudoVersionRev(m.Version)
+			return rev
+		}
+		if lookupEnv != nil {
+			return lookupEnv(name)
+		}
+		return "${" + name + "}"
+	})
+}
+
+// ExpandedBuildEnvs returns BuildEnvs with placeholders (e.g. "${VERSION}") expanded and environment variables references
+// expanded against the given environment (e.g. os.Environ() merged with variables from the env file).
+func (m Package) ExpandedBuildEnvs(env envars.EnvSlice) []string {
+	return expandAll(m.BuildEnvs, m.Module, env.Getenv)
+}
+
+// ExpandedBuildFlags returns BuildFlags with placeholders (e.g. "${VERSION}") expanded and environment variables
+// references expanded against the given environment.
+func (m Package) ExpandedBuildFlags(env envars.EnvSlice) []string {
+	return expandAll(m.BuildFlags, m.Module, env.Getenv)
+}
+
+func expandAll(s []string, m module.Version, lookupEnv func(string) string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(s))
+	for _, e := range s {
+		ret = append(ret, expandPlaceholders(e, m, lookupEnv))
+	}
+	return ret
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// goBuildFlags are flags accepted by go build, by whether they are boolean.
+var goBuildFlags = map[string]bool{
+	"a": true, "asan": true, "buildvcs": true, "cover": true, "linkshared": true, "modcacherw": true, "msan": true,
+	"n": true, "race": true, "trimpath": true, "v": true, "work": true, "x": true,
+
+	"asmflags": false, "buildmode": false, "compiler": false, "covermode": false, "coverpkg": false, "gccgoflags": false,
+	"gcflags": false, "installsuffix": false, "ldflags": false, "overlay": false, "p": false, "pgo": false,
+	"pkgdir": false, "tags": false, "toolexec": false,
+}
+
+// bingoBuildFlags are go build flags set by bingo itself.
+var bingoBuildFlags = []string{"o", "mod", "modfile", "C"}
+
+// buildFlagName returns name of the flag (e.g. "tags" for "--tags=netgo").
+func buildFlagName(flag string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(flag, "-"), "-"), "=")
+	return name
+}
+
+// ValidateBuildOptions returns error if build environment variables are malformed or build flags are not accepted by
+// go build.
+func (m Package) ValidateBuildOptions() error {
+	for _, e := range m.BuildEnvs {
+		if name, _, _ := strings.Cut(e, "="); !envNameRegexp.MatchString(name) {
+			return errors.Newf("invalid build environment variable %q; expected NAME=value", e)
+		}
+	}
+
+	for i := 0; i < len(m.BuildFlags); i++ {
+		f := m.BuildFlags[i]
+		name, hasValue := buildFlagName(f), strings.Contains(f, "=")
+		if !strings.HasPrefix(f, "-") || name == "" {
+			return errors.Newf("invalid build flag %q; expected -flag or -flag=value", f)
+		}
+		for _, b := range bingoBuildFlags {
+			if name == b {
+				return errors.Newf("build flag %q is set by bingo and can't be overridden", f)
+			}
+		}
+		isBool, ok := goBuildFlags[name]
+		if !ok {
+			return errors.Newf("build flag %q is not accepted by go build", f)
+		}
+		if !isBool && !hasValue {
+			// Value given as a separate token (e.g. "-tags netgo").
+			if i+1 >= len(m.BuildFlags) {
+				return errors.Newf("build flag %q requires a value", f)
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// ReproducibleBuildFlags are go build flags added in reproducible mode. See Config.Reproducible.
+var ReproducibleBuildFlags = []string{"-trimpath", "-buildvcs=false"}
+
+// reproducibleBuildFlags returns ReproducibleBuildFlags not specified (with any value) in the given build flags.
+func reproducibleBuildFlags(flags []string) []string {
+	var ret []string
+ReproducibleLoop:
+	for _, r := range ReproducibleBuildFlags {
+		for _, f := range flags {
+			if buildFlagName(f) == buildFlagName(r) {
+				continue ReproducibleLoop
+			}
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// ReproducibleBuildOptions returns build environment variables (including the ones from the env file) and flags
+// amended for reproducible build: cgo is disabled unless CGO_ENABLED is set by the tool and ReproducibleBuildFlags are
+// added, unless set by the tool. See Config.Reproducible.
+func ReproducibleBuildOptions(envs, flags []string) ([]string, []string) {
+	if _, ok := envars.EnvSlice(envs).Lookup("CGO_ENABLED"); !ok {
+		envs = append([]string{"CGO_ENABLED=0"}, envs...)
+	}
+	return envs, append(reproducibleBuildFlags(flags), flags...)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+	"golang.org/x/mod/module"
+)
+
+func TestSplitMeta(t *testing.T) {
+	for _, tcase := range []struct {
+		line     string
+		expected []string
+	}{
+		{line: "", expected: nil},
+		{line: "cmd/tool  CGO_ENABLED=1 -tags=a,b", expected: []string{"cmd/tool", "CGO_ENABLED=1", "-tags=a,b"}},
+		{line: `-ldflags="-X main.version=${VERSION} -s"`, expected: []string{"-ldflags=-X main.version=${VERSION} -s"}},
+		{line: `CGO_CFLAGS='-I /a "b"' -x`, expected: []string{`CGO_CFLAGS=-I /a "b"`, "-x"}},
+		{line: `-ldflags="-X \"main.v=a b\" \x" a\ b`, expected: []string{`-ldflags=-X "main.v=a b" \x`, "a b"}},
+		{line: `-ldflags="-s -w`, expected: []string{"-ldflags=-s -w"}},
+		{line: `"" -x`, expected: []string{"", "-x"}},
+	} {
+		t.Run(tcase.line, func(t *testing.T) {
+			tokens := splitMeta(tcase.line)
+			testutil.Equals(t, tcase.expected, tokens)
+			testutil.Equals(t, tokens, splitMeta(joinMeta(tokens)))
+		})
+	}
+}
+
+func TestQuoteMeta(t *testing.T) {
+	testutil.Equals(t, "-tags=a,b", quoteMeta("-tags=a,b"))
+	testutil.Equals(t, `-ldflags="-X main.version=${VERSION}"`, quoteMeta("-ldflags=-X main.version=${VERSION}"))
+	testutil.Equals(t, `CGO_CFLAGS="-I \"a b\" \\x"`, quoteMeta(`CGO_CFLAGS=-I "a b" \x`))
+	testutil.Equals(t, `"a b"`, quoteMeta("a b"))
+	testutil.Equals(t, `""`, quoteMeta(""))
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	env := func(name string) string { return "env-" + name }
+
+	m := module.Version{Path: "github.com/bwplotka/bingo", Version: "v0.9.1-0.20240228105637-5f2f4e3e1a8b"}
+	testutil.Equals(t, "-X main.version=v0.9.1-0.20240228105637-5f2f4e3e1a8b -X main.commit=5f2f4e3e1a8b -X main.module=github.com/bwplotka/bingo",
+		expandPlaceholders("-X main.version=${VERSION} -X main.commit=${COMMIT} -X main.module=$MODULE", m, nil))
+	testutil.Equals(t, "-I${HOME}/include", expandPlaceholders("-I${HOME}/include", m, nil))
+	testutil.Equals(t, "-Ienv-HOME/include", expandPlaceholders("-I${HOME}/include", m, env))
+
+	m = module.Version{Path: "github.com/bwplotka/bingo", Version: "v0.9.0"}
+	testutil.Equals(t, "v0.9.0-", expandPlaceholders("${VERSION}-${COMMIT}", m, env))
+
+	p := Package{Module: m, BuildEnvs: []string{"A=${VERSION}", "B=${BAR}/x"}, BuildFlags: []string{"-ldflags=-X main.version=${VERSION} -X main.bar=$BAR$NOT_SET"}}
+	testutil.Equals(t, []string{"A=v0.9.0", "B=from-env-file/x"}, p.ExpandedBuildEnvs([]string{"BAR=from-env-file"}))
+	testutil.Equals(t, []string{"-ldflags=-X main.version=v0.9.0 -X main.bar=from-env-file"}, p.ExpandedBuildFlags([]string{"BAR=from-env-file"}))
+}
+
+func TestValidateBuildOptions(t *testing.T) {
+	for _, tcase := range []struct {
+		envs, flags []string
+		expectedErr string
+	}{
+		{envs: []string{"CGO_ENABLED=1", "CGO_CFLAGS=-I a"}, flags: []string{"-tags=netgo", "-trimpath", "--ldflags=-s -w", "-gcflags", "all=-N -l", "-race"}},
+		{envs: []string{"1A=1"}, expectedErr: `invalid build environment variable "1A=1"; expected NAME=value`},
+		{flags: []string{"-tags=a", "netgo"}, expectedErr: `invalid build flag "netgo"; expected -flag or -flag=value`},
+		{flags: []string{"-o=bin"}, expectedErr: `build flag "-o=bin" is set by bingo and can't be overridden`},
+		{flags: []string{"-modfile=a.mod"}, expectedErr: `build flag "-modfile=a.mod" is set by bingo and can't be overridden`},
+		{flags: []string{"-ldflag=-s"}, expectedErr: `build flag "-ldflag=-s" is not accepted by go build`},
+		{flags: []string{"-trimpath", "-tags"}, expectedErr: `build flag "-tags" requires a value`},
+	} {
+		t.Run("", func(t *testing.T) {
+			err := Package{BuildEnvs: tcase.envs, BuildFlags: tcase.flags}.ValidateBuildOptions()
+			if tcase.expectedErr != "" {
+				testutil.NotOk(t, err)
+				testutil.Equals(t, tcase.expectedErr, err.Error())
+				return
+			}
+			testutil.Ok(t, err)
+		})
+	}
+}
+
+func TestReproducibleBuildOptions(t *testing.T) {
+	envs, flags := ReproducibleBuildOptions(nil, nil)
+	testutil.Equals(t, []string{"CGO_ENABLED=0"}, envs)
+	testutil.Equals(t, []string{"-trimpath", "-buildvcs=false"}, flags)
+
+	envs, flags = ReproducibleBuildOptions([]string{"CGO_ENABLED=1", "CC=clang"}, []string{"--trimpath", "-buildvcs=true", "-tags=netgo"})
+	testutil.Equals(t, []string{"CGO_ENABLED=1", "CC=clang"}, envs)
+	testutil.Equals(t, []string{"--trimpath", "-buildvcs=true", "-tags=netgo"}, flags)
+}
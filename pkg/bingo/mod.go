@@ -4,6 +4,7 @@
 package bingo
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -30,9 +31,18 @@ const (
 	FakeRootModFileName = "go.mod"
 
 	NoDirectiveCommand = "bingo:no_directive_fetch"
-
-	PackageRenderablesPrintHeader = "Name\tBinary Name\tPackage @ Version\tBuild EnvVars\tBuild Flags\n" +
-		"----\t-----------\t-----------------\t-------------\t-----------\n"
+	// ConstraintCommand is a comment prefix for a semver version constraint (e.g "// bingo:constraint ~1.54") that
+	// upgrades of the tool (e.g. bingo get <tool>@latest) have to satisfy.
+	ConstraintCommand = "bingo:constraint"
+	// TrackCommand is a comment prefix for a Git branch or tag (e.g "// bingo:track main") that the pinned pseudo-version
+	// was resolved from. Such tools are re-resolved to the branch head on each bingo get.
+	TrackCommand = "bingo:track"
+	// EnvFileCommand is a comment prefix for a path of the dot env file, relative to the module file directory
+	// (e.g "// bingo:envfile build.env"), which is evaluated to get environment variables for the go build process.
+	EnvFileCommand = "bingo:envfile"
+
+	PackageRenderablesPrintHeader = "Name\tBinary Name\tPackage @ Version\tBuild EnvVars\tBuild Flags\tTracking\n" +
+		"----\t-----------\t-----------------\t-------------\t-----------\t--------\n"
 
 	metaComment = "Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT"
 )
@@ -59,6 +69,8 @@ type Package struct {
 	// BuildEnvs are environment variables to be used during go build process.
 	BuildEnvs envars.EnvSlice
 	// BuildFlags are flags to be used during go build process.
+	// Both BuildEnvs and BuildFlags can reference placeholders (e.g. "${VERSION}") and environment variables, see
+	// ExpandedBuildEnvs and ExpandedBuildFlags.
 	BuildFlags []string
 }
 
@@ -83,6 +95,9 @@ type ModFile struct {
 
 	directPackage               *Package
 	directivesAutoFetchDisabled bool
+	constraint                  string
+	track                       string
+	envFile                     string
 }
 
 // OpenModFile opens bingo mod file.
@@ -117,15 +132,71 @@ func (mf *ModFile) IsDirectivesAutoFetchDisabled() bool {
 	return mf.directivesAutoFetchDisabled
 }
 
+// Constraint returns version constraint (e.g. "~1.54") declared via ConstraintCommand comment or empty string if none.
+func (mf *ModFile) Constraint() string {
+	return mf.constraint
+}
+
+// SetConstraint replaces version constraint declared via ConstraintCommand comment. Empty constraint removes it.
+func (mf *ModFile) SetConstraint(constraint string) error {
+	return mf.setCommand(ConstraintCommand, constraint)
+}
+
+// Track returns Git branch or tag declared via TrackCommand comment or empty string if the tool is not tracking any.
+func (mf *ModFile) Track() string {
+	return mf.track
+}
+
+// SetTrack replaces Git branch or tag declared via TrackCommand comment. Empty value removes it.
+func (mf *ModFile) SetTrack(ref string) error {
+	return mf.setCommand(TrackCommand, ref)
+}
+
+// EnvFile returns path of the dot env file declared via EnvFileCommand comment or empty string if none.
+func (mf *ModFile) EnvFile() string {
+	return mf.envFile
+}
+
+// SetEnvFile replaces path of the dot env file declared via EnvFileCommand comment. Empty path removes it.
+func (mf *ModFile) SetEnvFile(envFile string) error {
+	return mf.setCommand(EnvFileCommand, envFile)
+}
+
+func (mf *ModFile) setCommand(command, value string) error {
+	if err := mf.DropComments(func(c string) bool {
+		return strings.HasPrefix(c, command+" ")
+	}); err != nil {
+		return err
+	}
+	if value != "" {
+		if err := mf.AddComment(command + " " + value); err != nil {
+			return err
+		}
+	}
+	return mf.Reload()
+}
+
 func (mf *ModFile) Reload() error {
 	if err := mf.File.Reload(); err != nil {
 		return err
 	}
 
+	mf.directivesAutoFetchDisabled = false
+	mf.constraint = ""
+	mf.track = ""
+	mf.envFile = ""
 	for _, c := range mf.Comments() {
 		if strings.Contains(c, NoDirectiveCommand) {
 			mf.directivesAutoFetchDisabled = true
-			break
+		}
+		if strings.HasPrefix(c, ConstraintCommand+" ") {
+			mf.constraint = strings.TrimSpace(strings.TrimPrefix(c, ConstraintCommand))
+		}
+		if strings.HasPrefix(c, TrackCommand+" ") {
+			mf.track = strings.TrimSpace(strings.TrimPrefix(c, TrackCommand))
+		}
+		if strings.HasPrefix(c, EnvFileCommand+" ") {
+			mf.envFile = strings.TrimSpace(strings.TrimPrefix(c, EnvFileCommand))
 		}
 	}
 
@@ -195,7 +266,7 @@ func CreateFromExistingOrNew(ctx context.Context, r *runner.Runner, logger *log.
 }
 
 func parseDirectPackageMeta(line string) (relPath string, buildEnv []string, buildFlags []string) {
-	elem := strings.Split(line, " ")
+	elem := splitMeta(line)
 	for i, l := range elem {
 		if l == "" {
 			continue
@@ -233,7 +304,7 @@ func (mf *ModFile) SetDirectRequire(target Package) (err error) {
 	meta = append(meta, target.BuildFlags...)
 
 	if len(meta) > 0 {
-		r.ExtraSuffixComment = strings.Join(meta, " ")
+		r.ExtraSuffixComment = joinMeta(meta)
 	}
 	mf.directPackage = &target
 	return mf.SetRequireDirectives(r)
@@ -242,24 +313,52 @@ func (mf *ModFile) SetDirectRequire(target Package) (err error) {
 // ModDirectPackage return the first direct package from bingo enhanced module file. The package suffix (if any) is
 // encoded in the line comment, in the same line as module and version.
 func ModDirectPackage(modFile string) (pkg Package, err error) {
+	pkg, _, _, err = modDirectPackageAndMeta(modFile)
+	return pkg, err
+}
+
+func modDirectPackageAndMeta(modFile string) (pkg Package, track, envFile string, err error) {
 	mf, err := OpenModFile(modFile)
 	if err != nil {
-		return Package{}, err
+		return Package{}, "", "", err
 	}
 	defer errcapture.Do(&err, mf.Close, "close")
 
 	if mf.directPackage == nil {
-		return Package{}, errors.Newf("no direct package found in %s; empty module?", mf.Filepath())
+		return Package{}, "", "", errors.Newf("no direct package found in %s; empty module?", mf.Filepath())
 	}
-	return *mf.directPackage, nil
+	return *mf.directPackage, mf.track, mf.envFile, nil
+}
+
+// EvalEnvFile evaluates the given dot env file (path relative to the module directory, as declared via EnvFileCommand)
+// and returns environment variables it defines. The file is evaluated in the module directory, the same way the
+// Variables.mk helper sources it, so expansions like "-I${PWD}/include" give the same result.
+func EvalEnvFile(ctx context.Context, modDir, envFile string) (envars.EnvSlice, error) {
+	if filepath.IsAbs(envFile) {
+		return nil, errors.Newf("env file %v has to be relative to the module directory %v", envFile, modDir)
+	}
+	absModDir, err := filepath.Abs(modDir)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(absModDir, envFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "read env file")
+	}
+	envs, err := envars.EvalVariables(ctx, bytes.NewReader(b), envars.MergeEnvSlices(os.Environ(), "PWD="+absModDir)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "evaluate env file %v", envFile)
+	}
+	return envs, nil
 }
 
 // ModIndirectModules return the all indirect mod from any module file.
 func ModIndirectModules(modFile string) (mods []module.Version, err error) {
-	m, err := mod.OpenFile(modFile)
+	m, err := mod.OpenFileForRead(modFile)
 	if err != nil {
 		return nil, err
 	}
+	defer errcapture.Do(&err, m.Close, "close")
 
 	for _, r := range m.RequireDirectives() {
 		if !r.Indirect {
@@ -275,6 +374,8 @@ func ModIndirectModules(modFile string) (mods []module.Version, err error) {
 type PackageVersionRenderable struct {
 	Version string
 	ModFile string
+	// Track is a Git branch or tag this version was resolved from, if tracked.
+	Track string
 }
 
 // PackageRenderable is used in variables.go. Modify with care.
@@ -287,6 +388,21 @@ type PackageRenderable struct {
 
 	BuildFlags   []string
 	BuildEnvVars []string
+	// EnvFile is a slash separated path of the dot env file, relative to the module directory, evaluated before the
+	// BuildEnvVars are applied. Empty if not declared.
+	EnvFile string
+}
+
+// BuildEnvVarsFor returns BuildEnvVars with placeholders (e.g. "${VERSION}") expanded for the given version. Other
+// references are kept, so they are expanded by the shell (or make) running the build.
+func (p PackageRenderable) BuildEnvVarsFor(version string) []string {
+	return expandAll(p.BuildEnvVars, module.Version{Path: p.ModPath, Version: version}, nil)
+}
+
+// BuildFlagsFor returns BuildFlags with placeholders (e.g. "${VERSION}") expanded for the given version. Other
+// references are kept, so they are expanded by the shell (or make) running the build.
+func (p PackageRenderable) BuildFlagsFor(version string) []string {
+	return expandAll(p.BuildFlags, module.Version{Path: p.ModPath, Version: version}, nil)
 }
 
 func (p PackageRenderable) ToPackages() []Package {
@@ -322,8 +438,9 @@ func (pkgs PackageRenderables) PrintTab(target string, w io.Writer) error {
 				p.Name,
 				p.Name + "-" + v.Version,
 				p.PackagePath + "@" + v.Version,
-				strings.Join(p.BuildEnvVars, " "),
-				strings.Join(p.BuildFlags, " "),
+				joinMeta(p.BuildEnvVars),
+				joinMeta(p.BuildFlags),
+				v.Track,
 			}
 			_, _ = fmt.Fprintln(tw, strings.Join(fields, "\t"))
 		}
@@ -350,7 +467,7 @@ ModLoop:
 			continue
 		}
 
-		pkg, err := ModDirectPackage(f)
+		pkg, track, envFile, err := modDirectPackageAndMeta(f)
 		if err != nil {
 			if remMalformed {
 				logger.Printf("found malformed module file %v, removing due to error: %v\n", f, err)
@@ -371,6 +488,7 @@ ModLoop:
 					pkgs[i].Versions = append([]PackageVersionRenderable{{
 						Version: pkg.Module.Version,
 						ModFile: filepath.Base(f),
+						Track:   track,
 					}}, pkgs[i].Versions...)
 					continue ModLoop
 				}
@@ -378,6 +496,7 @@ ModLoop:
 				pkgs[i].Versions = append(pkgs[i].Versions, PackageVersionRenderable{
 					Version: pkg.Module.Version,
 					ModFile: filepath.Base(f),
+					Track:   track,
 				})
 				continue ModLoop
 			}
@@ -385,10 +504,11 @@ ModLoop:
 		pkgs = append(pkgs, PackageRenderable{
 			Name: name,
 			Versions: []PackageVersionRenderable{
-				{Version: pkg.Module.Version, ModFile: filepath.Base(f)},
+				{Version: pkg.Module.Version, ModFile: filepath.Base(f), Track: track},
 			},
 			BuildFlags:   pkg.BuildFlags,
 			BuildEnvVars: pkg.BuildEnvs,
+			EnvFile:      filepath.ToSlash(envFile),
 
 			EnvVarName:  varName,
 			PackagePath: pkg.Path(),
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bwplotka/bingo/pkg/envars"
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/bwplotka/bingo/pkg/version"
 	"github.com/efficientgo/core/testutil"
@@ -262,4 +263,122 @@ require github.com/prometheus/prometheus v2.4.3+incompatible // CGO_ENABLED=1 GO
 			BuildFlags: []string{"-tags=yolo,linux"},
 		}, *mf.DirectPackage())
 	})
+	t.Run("with quoted build attributes", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "test.mod")
+		testutil.Ok(t, os.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/prometheus/prometheus v2.4.3+incompatible // cmd/prometheus CGO_CFLAGS='-I /a' -ldflags="-X main.version=${VERSION}" -trimpath
+`), os.ModePerm))
+
+		mf, err := OpenModFile(testFile)
+		testutil.Ok(t, err)
+
+		testutil.Equals(t, Package{
+			Module:     module.Version{Path: "github.com/prometheus/prometheus", Version: "v2.4.3+incompatible"},
+			RelPath:    "cmd/prometheus",
+			BuildEnvs:  []string{"CGO_CFLAGS=-I /a"},
+			BuildFlags: []string{"-ldflags=-X main.version=${VERSION}", "-trimpath"},
+		}, *mf.DirectPackage())
+		testutil.Ok(t, mf.Close())
+
+		expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/prometheus/prometheus v2.4.3+incompatible // cmd/prometheus CGO_CFLAGS="-I /a" -ldflags="-X main.version=${VERSION}" -trimpath
+`, testFile)
+	})
+	t.Run("with version constraint", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "test.mod")
+		testutil.Ok(t, os.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// bingo:constraint ~1.54
+require github.com/golangci/golangci-lint v1.54.2 // cmd/golangci-lint
+`), os.ModePerm))
+
+		mf, err := OpenModFile(testFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "~1.54", mf.Constraint())
+
+		testutil.Ok(t, mf.SetConstraint("<1.60"))
+		testutil.Equals(t, "<1.60", mf.Constraint())
+		testutil.Ok(t, mf.SetConstraint(""))
+		testutil.Equals(t, "", mf.Constraint())
+		testutil.Ok(t, mf.SetConstraint("1.54.x"))
+		testutil.Ok(t, mf.Close())
+
+		expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// bingo:constraint 1.54.x
+
+require github.com/golangci/golangci-lint v1.54.2 // cmd/golangci-lint
+`, testFile)
+	})
+	t.Run("with tracked branch", func(t *testing.T) {
+		modDir := t.TempDir()
+		testFile := filepath.Join(modDir, "buildable.mod")
+		testutil.Ok(t, os.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/bwplotka/bingo-testmodule v0.0.0-20221007091238-9d83f47b84c5 // buildable
+`), os.ModePerm))
+
+		mf, err := OpenModFile(testFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "", mf.Track())
+		testutil.Ok(t, mf.SetTrack("main"))
+		testutil.Equals(t, "main", mf.Track())
+		testutil.Ok(t, mf.Close())
+
+		pkgs, err := ListPinnedMainPackages(log.New(os.Stderr, "", 0), modDir, false)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []PackageVersionRenderable{{Version: "v0.0.0-20221007091238-9d83f47b84c5", ModFile: "buildable.mod", Track: "main"}}, pkgs[0].Versions)
+	})
+	t.Run("with env file", func(t *testing.T) {
+		modDir := t.TempDir()
+		testFile := filepath.Join(modDir, "buildable.mod")
+		testutil.Ok(t, os.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/bwplotka/bingo-testmodule v0.0.0-20221007091238-9d83f47b84c5 // buildable CGO_ENABLED=1
+`), os.ModePerm))
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "build.env"), []byte(`CGO_ENABLED=0
+CGO_CFLAGS="-I${PWD}/include -O2"
+`), os.ModePerm))
+
+		mf, err := OpenModFile(testFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "", mf.EnvFile())
+		testutil.Ok(t, mf.SetEnvFile("build.env"))
+		testutil.Equals(t, "build.env", mf.EnvFile())
+		testutil.Ok(t, mf.Close())
+
+		expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// bingo:envfile build.env
+
+require github.com/bwplotka/bingo-testmodule v0.0.0-20221007091238-9d83f47b84c5 // buildable CGO_ENABLED=1
+`, testFile)
+
+		pkgs, err := ListPinnedMainPackages(log.New(os.Stderr, "", 0), modDir, false)
+		testutil.Ok(t, err)
+		testutil.Equals(t, "build.env", pkgs[0].EnvFile)
+
+		envs, err := EvalEnvFile(context.Background(), modDir, pkgs[0].EnvFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, envars.EnvSlice{"CGO_ENABLED=0", "CGO_CFLAGS=-I" + modDir + "/include -O2"}, envs)
+
+		_, err = EvalEnvFile(context.Background(), modDir, filepath.Join(modDir, "build.env"))
+		testutil.NotOk(t, err)
+	})
 }
@@ -0,0 +1,316 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/efficientgo/core/errors"
+)
+
+// ShimsDir is a directory inside mod directory with shims of pinned tools, created by 'bingo get --shims'.
+const ShimsDir = "bin"
+
+// shimTemplate is a POSIX shell script that runs the version of the tool pinned in the nearest mod file, building it
+// with the same command as Variables.mk, if needed. Version and build options are parsed from the mod file at run time,
+// so shims don't have to be regenerated when it changes. Project level configuration (bingo.yaml) is rendered at
+// generation time.
+const shimTemplate = `#!/bin/sh
+# Auto generated shim managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# It runs {{ .Name }} in the version pinned in {{ .ModFile }} of the mod directory of this shim, (re)installing it in $GOBIN if needed.
+{{- if .RelModDir }}
+# If the nearest {{ .RelModDir }}/{{ .ModFile }} file (looking from the current directory up) is in another mod directory, its shim is
+# run instead or, if it has none, the version pinned there is run, built with the configuration of this shim.
+{{- end }}
+set -e
+
+mod_dir="$(cd "$(dirname "$0")/.." && pwd -P)"
+{{- if .RelModDir }}
+dir="$(pwd -P)"
+while :; do
+	if [ -f "${dir}/{{ .RelModDir }}/{{ .ModFile }}" ]; then
+		nearest="$(cd "${dir}/{{ .RelModDir }}" && pwd -P)"
+		if [ "${nearest}" != "${mod_dir}" ]; then
+			if [ -x "${nearest}/{{ .ShimsDir }}/{{ .Name }}" ]; then
+				exec "${nearest}/{{ .ShimsDir }}/{{ .Name }}" "$@"
+			fi
+			mod_dir="${nearest}"
+		fi
+		break
+	fi
+	[ "${dir}" = "/" ] && break
+	dir="$(dirname "${dir}")"
+done
+{{- end }}
+
+# Parse the direct require directive and build options, the same way as bingo does, into version, pkg, env_file,
+# build_env and build_flags variables. Build options are quoted for the shell, keeping environment variable references.
+version=""
+eval "$(awk -v reproducible="{{ if .Reproducible }}1{{ end }}" '
+function sq(s,    r, i, c) {
+	r = ""
+	for (i = 1; i <= length(s); i++) {
+		c = substr(s, i, 1)
+		r = r (c == "\047" ? "\047\\\047\047" : c)
+	}
+	return "\047" r "\047"
+}
+function split_meta(line, toks,    n, i, c, next_c, tok, in_tok, quote) {
+	n = 0; tok = ""; in_tok = 0; quote = ""
+	for (i = 1; i <= length(line); i++) {
+		c = substr(line, i, 1)
+		next_c = substr(line, i + 1, 1)
+		if (quote == "\047") {
+			if (c == "\047") { quote = ""; continue }
+		} else if (c == "\\" && i < length(line) && (quote == "" || next_c == "\"" || next_c == "\\")) {
+			i++; c = next_c
+		} else if (quote == "\"") {
+			if (c == "\"") { quote = ""; continue }
+		} else if (c == "\047" || c == "\"") {
+			quote = c; in_tok = 1; continue
+		} else if (c == " " || c == "\t") {
+			if (in_tok) { toks[++n] = tok; tok = ""; in_tok = 0 }
+			continue
+		}
+		tok = tok c; in_tok = 1
+	}
+	if (in_tok) toks[++n] = tok
+	return n
+}
+function placeholders(s,    r, rest, name, n) {
+	r = ""
+	while ((n = index(s, "$")) > 0) {
+		r = r substr(s, 1, n - 1)
+		rest = substr(s, n + 1)
+		if (match(rest, /^\{[A-Za-z_][A-Za-z0-9_]*\}/)) {
+			name = substr(rest, 2, RLENGTH - 2)
+		} else if (match(rest, /^[A-Za-z_][A-Za-z0-9_]*/)) {
+			name = substr(rest, 1, RLENGTH)
+		} else {
+			r = r "$"; s = rest; continue
+		}
+		s = substr(rest, RLENGTH + 1)
+		if (name == "VERSION") r = r version
+		else if (name == "MODULE") r = r module
+		else if (name == "COMMIT") r = r commit
+		else r = r "${" name "}"
+	}
+	return r s
+}
+function quote_expand(s,    r, i, c, rest) {
+	if (s ~ /^[A-Za-z0-9_.\/:,=@%+-]+$/) return s
+	r = "\""
+	for (i = 1; i <= length(s); i++) {
+		c = substr(s, i, 1)
+		if (c == "$") {
+			rest = substr(s, i + 1)
+			if (match(rest, /^\{[A-Za-z_][A-Za-z0-9_]*\}/) || match(rest, /^[A-Za-z_][A-Za-z0-9_]*/)) {
+				r = r c substr(rest, 1, RLENGTH); i += RLENGTH; continue
+			}
+			r = r "\\$"; continue
+		}
+		if (c == "\"" || c == "\\" || c == "\140") r = r "\\"
+		r = r c
+	}
+	return r "\""
+}
+function flag_name(f) {
+	sub(/^-/, "", f); sub(/^-/, "", f); sub(/=.*/, "", f)
+	return f
+}
+$1 == "//" && $2 == "bingo:envfile" {
+	env_file = $0
+	sub(/^[ \t]*\/\/[ \t]*bingo:envfile[ \t]+/, "", env_file); sub(/[ \t]+$/, "", env_file)
+}
+$1 == "require" && $2 != "(" && module == "" {
+	module = $2; version = $3
+	meta = index($0, "//") ? substr($0, index($0, "//") + 2) : ""
+}
+END {
+	if (module == "") exit 1
+	v = version; sub(/\+incompatible$/, "", v)
+	n = split(v, parts, "-")
+	commit = ""
+	if (n >= 3 && length(parts[n]) == 12 && substr(parts[n - 1], length(parts[n - 1]) - 13) ~ /^[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]$/) commit = parts[n]
+
+	rel_path = ""; envs = ""; flags = ""; n_flags = 0
+	n = split_meta(meta, toks)
+	for (i = 1; i <= n; i++) {
+		if (toks[i] == "") continue
+		if (substr(toks[i], 1, 1) == "-") {
+			for (; i <= n; i++) user_flags[++n_flags] = placeholders(toks[i])
+			break
+		}
+		if (index(toks[i], "=") == 0) { rel_path = toks[i]; continue }
+		eq = index(toks[i], "=")
+		envs = envs " " substr(toks[i], 1, eq) quote_expand(placeholders(substr(toks[i], eq + 1)))
+	}
+	if (reproducible != "") {
+		split("-trimpath -buildvcs=false", repro, " ")
+		for (r = 1; r <= 2; r++) {
+			set = 0
+			for (i = 1; i <= n_flags; i++) if (flag_name(user_flags[i]) == flag_name(repro[r])) set = 1
+			if (!set) flags = flags " " repro[r]
+		}
+	}
+	for (i = 1; i <= n_flags; i++) flags = flags " " quote_expand(user_flags[i])
+
+	pkg = module
+	if (rel_path != "" && rel_path != ".") pkg = module "/" rel_path
+	print "version=" sq(version)
+	print "pkg=" sq(pkg)
+	print "env_file=" sq(env_file)
+	print "build_env=" sq(substr(envs, 2))
+	print "build_flags=" sq(substr(flags, 2))
+}' "${mod_dir}/{{ .ModFile }}")"
+if [ -z "${version}" ]; then
+	echo "bingo shim: no pinned module found in ${mod_dir}/{{ .ModFile }}" >&2
+	exit 1
+fi
+
+GO="${GO:-go}"
+gobin="${GOBIN:-$("${GO}" env GOBIN)}"
+gobin="${gobin:-$("${GO}" env GOPATH | cut -d: -f1)/bin}"
+bin="${gobin}/{{ .Name }}-${version}"
+{{- if .Binsum }}
+
+# binsum_ok succeeds if the binary matches sha256 recorded in {{ binsumFile .ModFile }} for the host platform and Go version, or if
+# nothing is recorded.
+binsum_ok() {
+	want="$(grep -F "${version} $("${GO}" env GOHOSTOS)/$("${GO}" env GOHOSTARCH) $("${GO}" env GOVERSION) " "${mod_dir}/{{ binsumFile .ModFile }}" 2>/dev/null | cut -d " " -f 4)"
+	if command -v sha256sum >/dev/null 2>&1; then
+		got="$(sha256sum "${bin}" | cut -d " " -f 1)"
+	else
+		got="$(shasum -a 256 "${bin}" | cut -d " " -f 1)"
+	fi
+	[ -z "${want}" ] || [ "${want}" = "${got}" ]
+}
+{{- end }}
+
+install=""
+if [ ! -x "${bin}" ] || [ "${mod_dir}/{{ .ModFile }}" -nt "${bin}" ] || { [ -n "${env_file}" ] && [ "${mod_dir}/${env_file}" -nt "${bin}" ]; }; then
+	install="yes"
+{{- if .Binsum }}
+elif ! binsum_ok; then
+	echo "bingo shim: sha256 of existing ${bin} does not match the one recorded in {{ binsumFile .ModFile }}; reinstalling" >&2
+	install="yes"
+{{- end }}
+fi
+if [ -n "${install}" ]; then
+	echo "(re)installing ${bin}" >&2
+	(
+		cd "${mod_dir}"
+{{- if .Reproducible }}
+		export {{ shReproducibleEnv .ReproducibleEnv }}
+{{- end }}
+		if [ -n "${env_file}" ]; then
+			set -a && . "./${env_file}" && set +a
+		fi
+		export {{ shHostEnv .GoEnv }}
+		if [ -n "${build_env}" ]; then
+			eval "export ${build_env}"
+		fi
+		eval "set -- ${build_flags}"
+		"${GO}" build "$@" -mod=mod -modfile="{{ .ModFile }}" -o="${bin}" "${pkg}"
+	) >&2
+{{- if .Binsum }}
+	if ! binsum_ok; then
+		echo "sha256 of ${bin} does not match the one recorded in {{ binsumFile .ModFile }}; either the build is not reproducible or the checksum (or binary) was tampered with" >&2
+		rm -f "${bin}"
+		exit 1
+	fi
+{{- end }}
+fi
+
+exec "${bin}" "$@"
+`
+
+type shimData struct {
+	Version string
+	Name    string
+	// RelModDir is a mod directory relative to the project root, used to find the nearest mod file. Empty if mod
+	// directory is not local to the project.
+	RelModDir string
+	ShimsDir  string
+	// ModFile is the name of the module file the tool is pinned in.
+	ModFile string
+
+	GoEnv           []EnvVar
+	Reproducible    bool
+	ReproducibleEnv []string
+	Binsum          bool
+}
+
+// ShimsEnabled returns true if shims are maintained in the given mod directory.
+func ShimsEnabled(modDir string) (bool, error) {
+	s, err := os.Stat(filepath.Join(modDir, ShimsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.IsDir(), nil
+}
+
+// GenShims (re)generates executable shims in ShimsDir for all pinned tools, except arrays which have no single version.
+// Shims of tools that are not pinned anymore are removed.
+func GenShims(relModDir, version string, cfg Config, pkgs []PackageRenderable) error {
+	t, err := template.New("shim").Funcs(templateFuncs).Funcs(template.FuncMap{
+		"shReproducibleEnv": shReproducibleEnv,
+		"shHostEnv":         shHostEnv,
+	}).Parse(shimTemplate)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+
+	dir := filepath.Join(relModDir, ShimsDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.Wrap(err, "rm")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "create shims dir")
+	}
+
+	data := shimData{
+		Version:         version,
+		ShimsDir:        ShimsDir,
+		GoEnv:           cfg.GoEnvVars(),
+		Reproducible:    cfg.Reproducible,
+		ReproducibleEnv: cfg.ReproducibleEnv(),
+		Binsum:          cfg.Binsum,
+	}
+	if filepath.IsLocal(relModDir) {
+		data.RelModDir = filepath.ToSlash(filepath.Clean(relModDir))
+	}
+	for _, p := range pkgs {
+		if len(p.Versions) != 1 {
+			continue
+		}
+		data.Name, data.ModFile = p.Name, p.Versions[0].ModFile
+		if err := genShim(t, filepath.Join(dir, p.Name), data); err != nil {
+			return errors.Wrap(err, p.Name)
+		}
+	}
+	return nil
+}
+
+func genShim(t *template.Template, f string, data shimData) (err error) {
+	fb, err := os.OpenFile(f, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return errors.Wrap(err, "create")
+	}
+	defer func() {
+		if cerr := fb.Close(); cerr != nil {
+			if err != nil {
+				err = errors.Wrapf(err, "additionally error on close: %v", cerr)
+				return
+			}
+			err = cerr
+		}
+	}()
+	return t.Execute(fb, data)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/core/testutil"
+)
+
+const shimTestModFile = `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// bingo:envfile build.env
+require github.com/example/tool %s // cmd/tool CGO_ENABLED=1 "-ldflags=-X main.home=${HOME}"
+`
+
+// fakeGo is a go command that "builds" a script printing the build command, its selected environment and arguments.
+const fakeGo = `#!/bin/sh
+if [ "$1" = "env" ]; then
+	shift
+	for e in "$@"; do
+		case "${e}" in
+		GOBIN) echo "${GOBIN}" ;;
+		GOVERSION) echo "go1.test" ;;
+		GOHOSTOS) echo "testos" ;;
+		GOHOSTARCH) echo "testarch" ;;
+		*) echo "" ;;
+		esac
+	done
+	exit 0
+fi
+for a in "$@"; do
+	case "${a}" in
+	-o=*) out="${a#-o=}" ;;
+	esac
+done
+printf '#!/bin/sh\necho "built: %s CGO_ENABLED=%s GOFLAGS=%s FROM_ENV_FILE=%s GOOS=%s $*"\n' "$*" "${CGO_ENABLED}" "${GOFLAGS}" "${FROM_ENV_FILE}" "${GOOS}" > "${out}"
+chmod +x "${out}"
+`
+
+func TestGenShims(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are POSIX shell scripts")
+	}
+
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, ".bingo")
+	gobin := filepath.Join(dir, "gobin")
+	testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(gobin, os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, "go"), []byte(fakeGo), 0755))
+
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "tool.mod"), []byte(strings.ReplaceAll(shimTestModFile, "%s", "v1.2.3")), os.ModePerm))
+	testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "build.env"), []byte("FROM_ENV_FILE=yes\n"), os.ModePerm))
+
+	pkgs := []PackageRenderable{
+		{
+			Name:         "tool",
+			ModPath:      "github.com/example/tool",
+			PackagePath:  "github.com/example/tool/cmd/tool",
+			Versions:     []PackageVersionRenderable{{Version: "v1.2.3", ModFile: "tool.mod"}},
+			BuildEnvVars: []string{"CGO_ENABLED=1"},
+			BuildFlags:   []string{"-ldflags=-X main.home=${HOME}"},
+			EnvFile:      "build.env",
+		},
+		{Name: "arr", Versions: []PackageVersionRenderable{{Version: "v1.0.0", ModFile: "arr.mod"}, {Version: "v2.0.0", ModFile: "arr.1.mod"}}},
+	}
+	cfg := Config{Reproducible: true}
+	testutil.Ok(t, os.MkdirAll(filepath.Join(modDir, ShimsDir, "removed"), os.ModePerm))
+	// Shims look for the nearest mod directory only if it's given relatively to the project root.
+	t.Chdir(dir)
+	testutil.Ok(t, GenShims(".bingo", "v0.test", cfg, pkgs))
+
+	entries, err := os.ReadDir(filepath.Join(modDir, ShimsDir))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(entries))
+	testutil.Equals(t, "tool", entries[0].Name())
+
+	runShim := func(t *testing.T, shim, wd string) (string, error) {
+		t.Helper()
+
+		cmd := exec.Command(shim, "a", "b c")
+		cmd.Dir = wd
+		cmd.Env = append(os.Environ(), "GOBIN="+gobin, "GO="+filepath.Join(dir, "go"), "HOME=/home/bingo", "GOFLAGS=-mod=vendor")
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	t.Run("build", func(t *testing.T) {
+		out, err := runShim(t, filepath.Join(modDir, ShimsDir, "tool"), dir)
+		testutil.Ok(t, err, out)
+		testutil.Equals(t, "(re)installing "+gobin+"/tool-v1.2.3\n"+
+			"built: build -trimpath -buildvcs=false -ldflags=-X main.home=/home/bingo -mod=mod -modfile=tool.mod -o="+gobin+"/tool-v1.2.3 github.com/example/tool/cmd/tool "+
+			"CGO_ENABLED=1 GOFLAGS= FROM_ENV_FILE=yes GOOS=testos a b c\n", out)
+	})
+	t.Run("installed", func(t *testing.T) {
+		bin := filepath.Join(gobin, "tool-v1.2.3")
+		testutil.Ok(t, os.WriteFile(bin, []byte("#!/bin/sh\necho \"tool v1.2.3 $*\"\n"), 0755))
+		future := time.Now().Add(time.Hour)
+		testutil.Ok(t, os.Chtimes(bin, future, future))
+
+		out, err := runShim(t, filepath.Join(modDir, ShimsDir, "tool"), dir)
+		testutil.Ok(t, err, out)
+		testutil.Equals(t, "tool v1.2.3 a b c\n", out)
+	})
+	t.Run("binsum mismatch", func(t *testing.T) {
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "tool.binsum"), []byte("v1.2.3 testos/testarch go1.test 0000\n"), os.ModePerm))
+		defer func() { testutil.Ok(t, os.Remove(filepath.Join(modDir, "tool.binsum"))) }()
+		testutil.Ok(t, GenShims(".bingo", "v0.test", Config{Reproducible: true, Binsum: true}, pkgs))
+
+		// Installed binary is rebuilt, but the built one does not match either.
+		out, err := runShim(t, filepath.Join(modDir, ShimsDir, "tool"), dir)
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "bingo shim: sha256 of existing "+gobin+"/tool-v1.2.3 does not match the one recorded in tool.binsum; reinstalling\n"+
+			"(re)installing "+gobin+"/tool-v1.2.3\n"+
+			"sha256 of "+gobin+"/tool-v1.2.3 does not match the one recorded in tool.binsum; either the build is not reproducible or the checksum (or binary) was tampered with\n", out)
+		_, err = os.Stat(filepath.Join(gobin, "tool-v1.2.3"))
+		testutil.Assert(t, os.IsNotExist(err))
+	})
+	t.Run("nearest mod dir", func(t *testing.T) {
+		other := filepath.Join(dir, "other")
+		otherModDir := filepath.Join(other, ".bingo")
+		testutil.Ok(t, os.MkdirAll(filepath.Join(other, "sub"), os.ModePerm))
+		testutil.Ok(t, os.MkdirAll(otherModDir, os.ModePerm))
+		testutil.Ok(t, os.WriteFile(filepath.Join(otherModDir, "tool.mod"), []byte(strings.ReplaceAll(shimTestModFile, "%s", "v1.2.4")), os.ModePerm))
+		testutil.Ok(t, os.WriteFile(filepath.Join(otherModDir, "build.env"), []byte("FROM_ENV_FILE=other\n"), os.ModePerm))
+
+		otherPkgs := []PackageRenderable{pkgs[0]}
+		otherPkgs[0].Versions = []PackageVersionRenderable{{Version: "v1.2.4", ModFile: "tool.mod"}}
+		t.Chdir(other)
+		testutil.Ok(t, GenShims(".bingo", "v0.test", Config{}, otherPkgs))
+		out, err := runShim(t, filepath.Join(modDir, ShimsDir, "tool"), filepath.Join(other, "sub"))
+		testutil.Ok(t, err, out)
+		// Shim of other project is run, and other project is not reproducible.
+		testutil.Equals(t, "(re)installing "+gobin+"/tool-v1.2.4\n"+
+			"built: build -ldflags=-X main.home=/home/bingo -mod=mod -modfile=tool.mod -o="+gobin+"/tool-v1.2.4 github.com/example/tool/cmd/tool "+
+			"CGO_ENABLED=1 GOFLAGS=-mod=vendor FROM_ENV_FILE=other GOOS=testos a b c\n", out)
+
+		// Without shims, version pinned in the nearest mod directory is built with the configuration of this shim.
+		testutil.Ok(t, os.RemoveAll(filepath.Join(otherModDir, ShimsDir)))
+		testutil.Ok(t, os.Remove(filepath.Join(gobin, "tool-v1.2.4")))
+		out, err = runShim(t, filepath.Join(modDir, ShimsDir, "tool"), filepath.Join(other, "sub"))
+		testutil.Ok(t, err, out)
+		testutil.Equals(t, "(re)installing "+gobin+"/tool-v1.2.4\n"+
+			"built: build -trimpath -buildvcs=false -ldflags=-X main.home=/home/bingo -mod=mod -modfile=tool.mod -o="+gobin+"/tool-v1.2.4 github.com/example/tool/cmd/tool "+
+			"CGO_ENABLED=1 GOFLAGS= FROM_ENV_FILE=other GOOS=testos a b c\n", out)
+	})
+	t.Run("mod file changed", func(t *testing.T) {
+		// Version and build options are read from the mod file at run time, so shims don't have to be regenerated.
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "tool.mod"), []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/example/tool v1.3.0-0.20990101000000-abcdefabcdef // cmd/other CGO_ENABLED=0 X="it's ${HOME}" -trimpath=false "-ldflags=-X main.version=${VERSION} -X main.commit=$COMMIT -X 'main.price=\\$1'"
+
+require github.com/example/dep v1.0.0 // indirect
+`), os.ModePerm))
+
+		out, err := runShim(t, filepath.Join(modDir, ShimsDir, "tool"), dir)
+		testutil.Ok(t, err, out)
+		testutil.Equals(t, "(re)installing "+gobin+"/tool-v1.3.0-0.20990101000000-abcdefabcdef\n"+
+			"built: build -buildvcs=false -trimpath=false -ldflags=-X main.version=v1.3.0-0.20990101000000-abcdefabcdef -X main.commit=abcdefabcdef -X 'main.price=$1' "+
+			"-mod=mod -modfile=tool.mod -o="+gobin+"/tool-v1.3.0-0.20990101000000-abcdefabcdef github.com/example/tool/cmd/other "+
+			"CGO_ENABLED=0 GOFLAGS= FROM_ENV_FILE= GOOS=testos a b c\n", out)
+	})
+}
@@ -0,0 +1,55 @@
+# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo v0.test. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+BINGO_DIR := $(dir $(lastword $(MAKEFILE_LIST)))
+GOPATH ?= $(shell go env GOPATH)
+GOBIN  ?= $(firstword $(subst :, ,${GOPATH}))/bin
+GO     ?= $(shell which go)
+
+# GOBIN with escaped spaces, so tool paths work both as Make targets and in shell.
+bingo_empty :=
+bingo_space := $(bingo_empty) $(bingo_empty)
+BINGO_GOBIN := $(subst $(bingo_space),\$(bingo_space),$(GOBIN))
+
+# Ensure bingo-managed tools are always built for the host platform,
+# even when GOOS/GOARCH are set for cross-compilation of other targets.
+GOHOSTOS     ?= $(shell $(GO) env GOHOSTOS)
+GOHOSTARCH   ?= $(shell $(GO) env GOHOSTARCH)
+GOHOSTARM    ?= $(shell $(GO) env GOHOSTARM)
+
+# Binaries are verified against sha256 checksums recorded by 'bingo get' in <tool>.binsum files, also before existing
+# binaries are used.
+BINGO_GOVERSION := $(shell $(GO) env GOVERSION)
+BINGO_SHA256    := $(if $(shell command -v sha256sum 2>/dev/null),sha256sum,shasum -a 256)
+# bingo_binsum_ok succeeds if binary $(3) built from version $(2) matches sha256 recorded in binsum file $(1) for the
+# host platform and Go version, or if nothing is recorded.
+bingo_binsum_ok = want="$$(grep -F "$(2) $(GOHOSTOS)/$(GOHOSTARCH) $(BINGO_GOVERSION) " "$(1)" 2>/dev/null | cut -d " " -f 4)" && { [ -z "$$want" ] || [ "$$want" = "$$($(BINGO_SHA256) "$(3)" | cut -d " " -f 1)" ]; }
+
+.PHONY: .bingo-binsum-verify
+.bingo-binsum-verify:
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+BINGO_TOOLS_HASH := 2001322f4c5c1ed5fb6ae478969e583226e2a51f471e2ab73a9146aea5f030d3
+
+# Below generated variables ensure that every time a tool under each variable is invoked, the correct version
+# will be used; reinstalling only if needed.
+# For example for faillint variable:
+#
+# In your main Makefile (for non array binaries):
+#
+#include .bingo/Variables.mk # Assuming -dir was set to .bingo .
+#
+#command: $(FAILLINT)
+#	@echo "Running faillint"
+#	@$(FAILLINT) <flags/args..>
+#
+ARR_ARRAY := $(BINGO_GOBIN)/arr-v1.0.0 $(BINGO_GOBIN)/arr-v2.0.0
+$(ARR_ARRAY): $(BINGO_DIR)/arr.mod $(BINGO_DIR)/arr.1.mod .bingo-binsum-verify
+	@# Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
+	@if [ ! -f "$(GOBIN)/arr-v1.0.0" ] || [ "$(BINGO_DIR)/arr.mod" -nt "$(GOBIN)/arr-v1.0.0" ] || ! { $(call bingo_binsum_ok,$(BINGO_DIR)/arr.binsum,v1.0.0,$(GOBIN)/arr-v1.0.0) || { echo "sha256 of existing $(GOBIN)/arr-v1.0.0 does not match the one recorded in arr.binsum; reinstalling" >&2; false; }; }; then echo "(re)installing $(GOBIN)/arr-v1.0.0" && cd "$(BINGO_DIR)" && export GOAMD64= GOEXPERIMENT= GOFLAGS= CGO_ENABLED=0 && GOWORK=off GOPRIVATE='gitlab.example.com/*' GOOS=$(GOHOSTOS) GOARCH=$(GOHOSTARCH) GOARM=$(GOHOSTARM) "$(GO)" build -trimpath -buildvcs=false -mod=mod -modfile="arr.mod" -o="$(GOBIN)/arr-v1.0.0" "github.com/example/arr/cmd/arr" && { $(call bingo_binsum_ok,arr.binsum,v1.0.0,$(GOBIN)/arr-v1.0.0) || { echo "sha256 of $(GOBIN)/arr-v1.0.0 does not match the one recorded in arr.binsum; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "$(GOBIN)/arr-v1.0.0"; exit 1; }; }; fi
+	@if [ ! -f "$(GOBIN)/arr-v2.0.0" ] || [ "$(BINGO_DIR)/arr.1.mod" -nt "$(GOBIN)/arr-v2.0.0" ] || ! { $(call bingo_binsum_ok,$(BINGO_DIR)/arr.1.binsum,v2.0.0,$(GOBIN)/arr-v2.0.0) || { echo "sha256 of existing $(GOBIN)/arr-v2.0.0 does not match the one recorded in arr.1.binsum; reinstalling" >&2; false; }; }; then echo "(re)installing $(GOBIN)/arr-v2.0.0" && cd "$(BINGO_DIR)" && export GOAMD64= GOEXPERIMENT= GOFLAGS= CGO_ENABLED=0 && GOWORK=off GOPRIVATE='gitlab.example.com/*' GOOS=$(GOHOSTOS) GOARCH=$(GOHOSTARCH) GOARM=$(GOHOSTARM) "$(GO)" build -trimpath -buildvcs=false -mod=mod -modfile="arr.1.mod" -o="$(GOBIN)/arr-v2.0.0" "github.com/example/arr/cmd/arr" && { $(call bingo_binsum_ok,arr.1.binsum,v2.0.0,$(GOBIN)/arr-v2.0.0) || { echo "sha256 of $(GOBIN)/arr-v2.0.0 does not match the one recorded in arr.1.binsum; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "$(GOBIN)/arr-v2.0.0"; exit 1; }; }; fi
+
+FAILLINT := $(BINGO_GOBIN)/faillint-v1.5.0
+$(FAILLINT): $(BINGO_DIR)/faillint.mod $(BINGO_DIR)/build.env .bingo-binsum-verify
+	@# Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
+	@if [ ! -f "$(GOBIN)/faillint-v1.5.0" ] || [ "$(BINGO_DIR)/faillint.mod" -nt "$(GOBIN)/faillint-v1.5.0" ] || [ "$(BINGO_DIR)/build.env" -nt "$(GOBIN)/faillint-v1.5.0" ] || ! { $(call bingo_binsum_ok,$(BINGO_DIR)/faillint.binsum,v1.5.0,$(GOBIN)/faillint-v1.5.0) || { echo "sha256 of existing $(GOBIN)/faillint-v1.5.0 does not match the one recorded in faillint.binsum; reinstalling" >&2; false; }; }; then echo "(re)installing $(GOBIN)/faillint-v1.5.0" && cd "$(BINGO_DIR)" && export GOAMD64= GOEXPERIMENT= GOFLAGS= CGO_ENABLED=0 && set -a && . "./build.env" && set +a && GOWORK=off GOPRIVATE='gitlab.example.com/*' GOOS=$(GOHOSTOS) GOARCH=$(GOHOSTARCH) GOARM=$(GOHOSTARM) CGO_ENABLED=0 GOMODCACHE="$${HOME}/go mod" "$(GO)" build -buildvcs=false -tags=netgo -trimpath "-ldflags=-X main.version=v1.5.0 -X 'main.home=$${HOME}'" -mod=mod -modfile="faillint.mod" -o="$(GOBIN)/faillint-v1.5.0" "github.com/fatih/faillint" && { $(call bingo_binsum_ok,faillint.binsum,v1.5.0,$(GOBIN)/faillint-v1.5.0) || { echo "sha256 of $(GOBIN)/faillint-v1.5.0 does not match the one recorded in faillint.binsum; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "$(GOBIN)/faillint-v1.5.0"; exit 1; }; }; fi
+
@@ -0,0 +1,23 @@
+# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo v0.test. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Those variables will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
+# Use it in fish shell with: source .bingo/variables.fish
+if test -z "$GOBIN"
+	set -g GOBIN (go env GOBIN)
+end
+
+if test -z "$GOBIN"
+	set -g GOBIN (go env GOPATH)/bin
+end
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+set -g BINGO_TOOLS_HASH "2001322f4c5c1ed5fb6ae478969e583226e2a51f471e2ab73a9146aea5f030d3"
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+set -gx GOPRIVATE 'gitlab.example.com/*'
+
+
+set -g ARR_ARRAY "$GOBIN/arr-v1.0.0" "$GOBIN/arr-v2.0.0"
+
+set -g FAILLINT "$GOBIN/faillint-v1.5.0"
+
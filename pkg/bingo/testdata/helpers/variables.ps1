@@ -0,0 +1,25 @@
+# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo v0.test. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Those variables will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
+# Use it in PowerShell with: . .bingo/variables.ps1
+# Then invoke tool with: & $FAILLINT <flags/args..>
+$GOBIN = $env:GOBIN
+if (-not $GOBIN) {
+	$GOBIN = (go env GOBIN)
+}
+
+if (-not $GOBIN) {
+	$GOBIN = "$(go env GOPATH)/bin"
+}
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+$BINGO_TOOLS_HASH = "2001322f4c5c1ed5fb6ae478969e583226e2a51f471e2ab73a9146aea5f030d3"
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+$env:GOPRIVATE = 'gitlab.example.com/*'
+
+
+$ARR_ARRAY = @("$GOBIN/arr-v1.0.0", "$GOBIN/arr-v2.0.0")
+
+$FAILLINT = "$GOBIN/faillint-v1.5.0"
+
@@ -4,9 +4,8 @@
 package bingo
 
 var (
-	templatesByFileExt = map[string]string{
-		// TODO(bwplotka): We might want to play with better escaping to allow spaces in dir names.
-		// TODO(bwplotka): We get first binary as an example. It does not work if first one is array.
+	// templatesByHelper are templates of built-in helpers by helper name.
+	templatesByHelper = map[string]string{
 		"mk": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
 # All tools are designed to be build inside $GOBIN.
 BINGO_DIR := $(dir $(lastword $(MAKEFILE_LIST)))
@@ -14,33 +13,102 @@ GOPATH ?= $(shell go env GOPATH)
 GOBIN  ?= $(firstword $(subst :, ,${GOPATH}))/bin
 GO     ?= $(shell which go)
 
+# GOBIN with escaped spaces, so tool paths work both as Make targets and in shell.
+bingo_empty :=
+bingo_space := $(bingo_empty) $(bingo_empty)
+BINGO_GOBIN := $(subst $(bingo_space),\$(bingo_space),$(GOBIN))
+
 # Ensure bingo-managed tools are always built for the host platform,
 # even when GOOS/GOARCH are set for cross-compilation of other targets.
 GOHOSTOS     ?= $(shell $(GO) env GOHOSTOS)
 GOHOSTARCH   ?= $(shell $(GO) env GOHOSTARCH)
 GOHOSTARM    ?= $(shell $(GO) env GOHOSTARM)
+{{- if .Binsum }}
+
+# Binaries are verified against sha256 checksums recorded by 'bingo get' in <tool>.binsum files, also before existing
+# binaries are used.
+BINGO_GOVERSION := $(shell $(GO) env GOVERSION)
+BINGO_SHA256    := $(if $(shell command -v sha256sum 2>/dev/null),sha256sum,shasum -a 256)
+# bingo_binsum_ok succeeds if binary $(3) built from version $(2) matches sha256 recorded in binsum file $(1) for the
+# host platform and Go version, or if nothing is recorded.
+bingo_binsum_ok = want="$$(grep -F "$(2) $(GOHOSTOS)/$(GOHOSTARCH) $(BINGO_GOVERSION) " "$(1)" 2>/dev/null | cut -d " " -f 4)" && { [ -z "$$want" ] || [ "$$want" = "$$($(BINGO_SHA256) "$(3)" | cut -d " " -f 1)" ]; }
+
+.PHONY: .bingo-binsum-verify
+.bingo-binsum-verify:
+{{- end }}
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+BINGO_TOOLS_HASH := {{ .ToolsHash }}
 
 # Below generated variables ensure that every time a tool under each variable is invoked, the correct version
 # will be used; reinstalling only if needed.
-# For example for {{ with (index .MainPackages 0) }}{{ .Name }}{{ end }} variable:
+# For example for {{ with .Example }}{{ .Name }}{{ end }} variable:
 #
 # In your main Makefile (for non array binaries):
 #
 #include .bingo/Variables.mk # Assuming -dir was set to .bingo .
 #
-#command: $({{ with (index .MainPackages 0) }}{{ .EnvVarName }}{{ end }})
-#	@echo "Running {{ with (index .MainPackages 0) }}{{ .Name }}{{ end }}"
-#	@$({{ with (index .MainPackages 0) }}{{ .EnvVarName }}{{ end }}) <flags/args..>
+#command: $({{ with .Example }}{{ .EnvVarName }}{{ end }})
+#	@echo "Running {{ with .Example }}{{ .Name }}{{ end }}"
+#	@$({{ with .Example }}{{ .EnvVarName }}{{ end }}) <flags/args..>
 #
 {{- range $p := .MainPackages }}
-{{ $p.EnvVarName }} :={{- range $p.Versions }} $(GOBIN)/{{ $p.Name }}-{{ .Version }}{{- end }}
-$({{ $p.EnvVarName }}):{{- range $p.Versions }} $(BINGO_DIR)/{{ .ModFile }}{{- end }}
+{{ $p.EnvVarName }} :={{- range $p.Versions }} $(BINGO_GOBIN)/{{ $p.Name }}-{{ .Version }}{{- end }}
+$({{ $p.EnvVarName }}):{{- range $p.Versions }} $(BINGO_DIR)/{{ .ModFile }}{{- end }}{{ if $p.EnvFile }} $(BINGO_DIR)/{{ $p.EnvFile }}{{ end }}{{ if $.Binsum }} .bingo-binsum-verify{{ end }}
 	@# Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
 {{- range $p.Versions }}
-	@echo "(re)installing $(GOBIN)/{{ $p.Name }}-{{ .Version }}"
-	@cd $(BINGO_DIR) && GOWORK=off GOOS=$(GOHOSTOS) GOARCH=$(GOHOSTARCH) GOARM=$(GOHOSTARM) {{ range $p.BuildEnvVars }}{{ . }} {{ end }}$(GO) build {{ range $p.BuildFlags }}{{ . }} {{ end }}-mod=mod -modfile={{ .ModFile }} -o=$(GOBIN)/{{ $p.Name }}-{{ .Version }} "{{ $p.PackagePath }}"
+	@{{ if $.Binsum }}if [ ! -f "$(GOBIN)/{{ $p.Name }}-{{ .Version }}" ] || [ "$(BINGO_DIR)/{{ .ModFile }}" -nt "$(GOBIN)/{{ $p.Name }}-{{ .Version }}" ] ||{{ if $p.EnvFile }} [ "$(BINGO_DIR)/{{ $p.EnvFile }}" -nt "$(GOBIN)/{{ $p.Name }}-{{ .Version }}" ] ||{{ end }} ! { $(call bingo_binsum_ok,$(BINGO_DIR)/{{ binsumFile .ModFile }},{{ .Version }},$(GOBIN)/{{ $p.Name }}-{{ .Version }}) || { echo "sha256 of existing $(GOBIN)/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; reinstalling" >&2; false; }; }; then {{ end }}echo "(re)installing $(GOBIN)/{{ $p.Name }}-{{ .Version }}"{{ if $.Binsum }} && {{ else }}
+	@{{ end }}cd "$(BINGO_DIR)" && {{ if $.Reproducible }}export {{ range $.ReproducibleEnv }}{{ . }} {{ end }}CGO_ENABLED=0 && {{ end }}{{ if $p.EnvFile }}set -a && . "./{{ makeEscape $p.EnvFile }}" && set +a && {{ end }}GOWORK=off {{ range $.GoEnv }}{{ .Name }}={{ shellQuote .Value | makeEscape }} {{ end }}GOOS=$(GOHOSTOS) GOARCH=$(GOHOSTARCH) GOARM=$(GOHOSTARM) {{ range $p.BuildEnvVarsFor .Version }}{{ shellQuoteEnv . | makeEscape }} {{ end }}"$(GO)" build {{ if $.Reproducible }}{{ range reproducibleBuildFlags ($p.BuildFlagsFor .Version) }}{{ . }} {{ end }}{{ end }}{{ range $p.BuildFlagsFor .Version }}{{ shellQuoteExpand . | makeEscape }} {{ end }}-mod=mod -modfile="{{ .ModFile }}" -o="$(GOBIN)/{{ $p.Name }}-{{ .Version }}" "{{ $p.PackagePath }}"
+{{- if $.Binsum }} && { $(call bingo_binsum_ok,{{ binsumFile .ModFile }},{{ .Version }},$(GOBIN)/{{ $p.Name }}-{{ .Version }}) || { echo "sha256 of $(GOBIN)/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "$(GOBIN)/{{ $p.Name }}-{{ .Version }}"; exit 1; }; }; fi{{ end }}
 {{- end }}
 {{ end}}
+`,
+		"just": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Requires just 1.27+ (for source_directory() function).
+BINGO_DIR   := source_directory()
+BINGO_GO    := env_var_or_default('GO', 'go')
+BINGO_GOBIN := ` + "`" + `gobin="${GOBIN:-$(${GO:-go} env GOBIN)}"; echo "${gobin:-$(${GO:-go} env GOPATH | cut -d: -f1)/bin}"` + "`" + `
+
+{{- if .Binsum }}
+
+# Binaries are verified against sha256 checksums recorded by 'bingo get' in <tool>.binsum files, also before existing
+# binaries are used. bingo_binsum_ok succeeds if binary $3 built from version $2 matches sha256 recorded in binsum
+# file $1 for the host platform and Go version, or if nothing is recorded.
+BINGO_BINSUM_OK := 'bingo_binsum_ok() { want="$(grep -F "$2 $("${GO:-go}" env GOHOSTOS)/$("${GO:-go}" env GOHOSTARCH) $("${GO:-go}" env GOVERSION) " "$1" 2>/dev/null | cut -d " " -f 4)" && { [ -z "$want" ] || [ "$want" = "$(if command -v sha256sum >/dev/null 2>&1; then sha256sum "$3"; else shasum -a 256 "$3"; fi | cut -d " " -f 1)" ]; }; }'
+{{- end }}
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+BINGO_TOOLS_HASH := "{{ .ToolsHash }}"
+
+# Below generated variables point to the pinned version of each tool. Make your recipe depend on install-<tool>
+# recipe, so every time the tool is invoked, the correct version will be used; reinstalling only if needed.
+# For example for {{ with .Example }}{{ .Name }}{{ end }} variable:
+#
+# In your main justfile (for non array binaries):
+#
+#import '.bingo/tools.just' # Assuming -dir was set to .bingo .
+#
+#command: install-{{ with .Example }}{{ justName .Name }}{{ end }}
+#	@echo "Running {{ with .Example }}{{ .Name }}{{ end }}"
+#	@{{ "{{" }}{{ with .Example }}{{ .EnvVarName }}{{ end }}}} <flags/args..>
+#
+{{- range $p := .MainPackages }}
+
+{{ $p.EnvVarName }} := {{ range $i, $v := $p.Versions }}{{ if ne $i 0 }} + " " + {{ end }}BINGO_GOBIN + "/{{ $p.Name }}-{{ $v.Version }}"{{ end }}
+
+# (Re)install {{ $p.Name }} if binary is missing{{ if $.Binsum }}, does not match its binsum file{{ end }} or its pinned module file changed.
+install-{{ justName $p.Name }}:
+	@# Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
+{{- range $p.Versions }}
+	@{{ if $.Binsum }}{{ "{{" }}BINGO_BINSUM_OK}}; {{ end }}cd "{{ "{{" }}BINGO_DIR}}" && if [ ! -f "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" ] || [ "{{ .ModFile }}" -nt "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" ]{{ if $p.EnvFile }} || [ "{{ $p.EnvFile }}" -nt "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" ]{{ end }}{{ if $.Binsum }} || ! { bingo_binsum_ok "{{ binsumFile .ModFile }}" "{{ .Version }}" "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" || { echo "sha256 of existing {{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; reinstalling" >&2; false; }; }{{ end }}; then \
+		echo "(re)installing {{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" && \
+		{{ if $.Reproducible }}export {{ range $.ReproducibleEnv }}{{ . }} {{ end }}CGO_ENABLED=0 && {{ end }}{{ with $p.EnvFile }}set -a && . {{ shellQuote (print "./" .) | justEscape }} && set +a && {{ end }}GOWORK=off {{ range $.GoEnv }}{{ .Name }}={{ shellQuote .Value | justEscape }} {{ end }}GOOS="$("{{ "{{" }}BINGO_GO}}" env GOHOSTOS)" GOARCH="$("{{ "{{" }}BINGO_GO}}" env GOHOSTARCH)" GOARM="$("{{ "{{" }}BINGO_GO}}" env GOHOSTARM)" {{ range $p.BuildEnvVarsFor .Version }}{{ shellQuoteEnv . | justEscape }} {{ end }}"{{ "{{" }}BINGO_GO}}" build {{ if $.Reproducible }}{{ range reproducibleBuildFlags ($p.BuildFlagsFor .Version) }}{{ . }} {{ end }}{{ end }}{{ range $p.BuildFlagsFor .Version }}{{ shellQuoteExpand . | justEscape }} {{ end }}-mod=mod -modfile="{{ .ModFile }}" -o="{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" "{{ $p.PackagePath }}"
+{{- if $.Binsum }} && \
+		{ bingo_binsum_ok "{{ binsumFile .ModFile }}" "{{ .Version }}" "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" || { echo "sha256 of {{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "{{ "{{" }}BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}"; exit 1; }; }{{ end }}; \
+	fi
+{{- end }}
+{{- end }}
 `,
 		"env": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
 # All tools are designed to be build inside $GOBIN.
@@ -51,9 +119,385 @@ if [ -z "$GOBIN" ]; then
 	GOBIN="$(go env GOPATH)/bin"
 fi
 
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+BINGO_TOOLS_HASH="{{ .ToolsHash }}"
+{{- if .GoEnv }}
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+{{- range .GoEnv }}
+export {{ .Name }}={{ shellQuote .Value }}
+{{- end }}
+{{- end }}
+
 {{range $p := .MainPackages }}
+{{- if $p.EnvFile }}
+# {{ $p.Name }} is built with environment variables from {{ $p.EnvFile }} (relative to this file){{ with $p.BuildEnvVars }} and {{ join . " " }}{{ end }}.
+{{- end }}
 {{ $p.EnvVarName }}="{{- range $i, $v := $p.Versions }}{{- if ne $i 0}} {{ end }}${GOBIN}/{{ $p.Name }}-{{ $v.Version }}{{- end }}"
 {{ end}}
+`,
+		"envrc": `# Auto generated direnv helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# It puts pinned tools on PATH under their plain names (e.g. {{ with .Example }}{{ .Name }}{{ end }}), using symlinks in the project local directory,
+# so tools pinned by other projects never conflict. Array tools are not linked, as they have no single version.
+# Those links will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
+# Use it in your .envrc with:
+#
+#source_env .bingo/envrc # Assuming -dir was set to .bingo .
+#
+BINGO_GOBIN="${GOBIN:-$(go env GOBIN)}"
+BINGO_GOBIN="${BINGO_GOBIN:-$(go env GOPATH)/bin}"
+{{- if .GoEnv }}
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+{{- range .GoEnv }}
+export {{ .Name }}={{ shellQuote .Value }}
+{{- end }}
+{{- end }}
+
+# direnv sources this file from its directory, so the layout directory is local to the project.
+BINGO_LINKS_DIR="$(direnv_layout_dir)/bingo-bin"
+rm -rf "${BINGO_LINKS_DIR}"
+mkdir -p "${BINGO_LINKS_DIR}"
+{{- range $p := .MainPackages }}
+{{- if eq (len $p.Versions) 1 }}
+{{- with index $p.Versions 0 }}
+ln -s "${BINGO_GOBIN}/{{ $p.Name }}-{{ .Version }}" "${BINGO_LINKS_DIR}/{{ $p.Name }}"
+watch_file "{{ .ModFile }}"
+{{- end }}
+{{- end }}
+{{- end }}
+PATH_add "${BINGO_LINKS_DIR}"
+`,
+		"fish": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Those variables will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
+# Use it in fish shell with: source .bingo/variables.fish
+if test -z "$GOBIN"
+	set -g GOBIN (go env GOBIN)
+end
+
+if test -z "$GOBIN"
+	set -g GOBIN (go env GOPATH)/bin
+end
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+set -g BINGO_TOOLS_HASH "{{ .ToolsHash }}"
+{{- if .GoEnv }}
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+{{- range .GoEnv }}
+set -gx {{ .Name }} {{ fishQuote .Value }}
+{{- end }}
+{{- end }}
+
+{{range $p := .MainPackages }}
+set -g {{ $p.EnvVarName }}{{- range $p.Versions }} "$GOBIN/{{ $p.Name }}-{{ .Version }}"{{- end }}
+{{ end}}
+`,
+		"ps1": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Those variables will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
+# Use it in PowerShell with: . .bingo/variables.ps1
+# Then invoke tool with: & ${{ with .Example }}{{ .EnvVarName }}{{ end }} <flags/args..>
+$GOBIN = $env:GOBIN
+if (-not $GOBIN) {
+	$GOBIN = (go env GOBIN)
+}
+
+if (-not $GOBIN) {
+	$GOBIN = "$(go env GOPATH)/bin"
+}
+
+# Digest of all pinned tools module, configuration and env files (without Go version), e.g. for CI cache keys. See also 'bingo hash'.
+$BINGO_TOOLS_HASH = "{{ .ToolsHash }}"
+{{- if .GoEnv }}
+
+# Go environment configured in bingo.yaml, required to install pinned tools.
+{{- range .GoEnv }}
+$env:{{ .Name }} = {{ psQuote .Value }}
+{{- end }}
+{{- end }}
+
+{{range $p := .MainPackages }}
+${{ $p.EnvVarName }} = {{ if gt (len $p.Versions) 1 }}@({{ end }}{{- range $i, $v := $p.Versions }}{{- if ne $i 0}}, {{ end }}"$GOBIN/{{ $p.Name }}-{{ $v.Version }}"{{- end }}{{ if gt (len $p.Versions) 1 }}){{ end }}
+{{ end}}
+`,
+		"go": `// Code generated by bingo {{ .Version }} (https://github.com/bwplotka/bingo). DO NOT EDIT.
+
+// Package {{ .GoPackage }} provides paths of development tools pinned by bingo, e.g. for Magefiles or go:generate programs.
+// All tools are designed to be build inside $GOBIN. Use Ensure to (re)install them, if needed.
+package {{ .GoPackage }}
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// modDirPath is a path of the bingo mod directory, relative to the root of the Go module of this package.
+const modDirPath = {{ printf "%q" .ModuleToModDir }}
+
+type tool struct {
+	bin        string
+	version    string
+	modFile    string
+	envFile    string
+	binsumFile string
+	// build is a POSIX shell command building the tool in the mod directory, the same as in Variables.mk.
+	build string
+}
+
+var tools = []tool{
+{{- range $p := .MainPackages }}
+{{- range .Versions }}
+	{
+		bin:        {{ printf "%q" (print $p.Name "-" .Version) }},
+		version:    {{ printf "%q" .Version }},
+		modFile:    {{ printf "%q" .ModFile }},
+		envFile:    {{ printf "%q" $p.EnvFile }},
+		binsumFile: {{ if $.Binsum }}{{ printf "%q" (binsumFile .ModFile) }}{{ else }}""{{ end }},
+		build:      {{ goRawString ($.ShBuildCommand $p .) }},
+	},
+{{- end }}
+{{- end }}
+}
+
+func goCmd() string {
+	if g := os.Getenv("GO"); g != "" {
+		return g
+	}
+	return "go"
+}
+
+func goEnvValue(name string) string {
+	out, err := exec.Command(goCmd(), "env", name).Output()
+	if err != nil {
+		return os.Getenv(name)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GOBIN returns the directory pinned tools are installed in.
+var GOBIN = sync.OnceValue(func() string {
+	if gobin := goEnvValue("GOBIN"); gobin != "" {
+		return gobin
+	}
+	if gopath := filepath.SplitList(goEnvValue("GOPATH")); len(gopath) > 0 {
+		return filepath.Join(gopath[0], "bin")
+	}
+	return ""
+})
+
+// ModDir returns the bingo mod directory, looking for it from the working directory up, so it works from any directory
+// of the Go module of this package.
+var ModDir = sync.OnceValues(func() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, modDirPath, "go.mod")); err == nil {
+			return filepath.Join(dir, modDirPath), nil
+		}
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("bingo mod directory %v not found in %v or any parent directory", modDirPath, wd)
+		}
+	}
+})
+{{- range $p := .MainPackages }}
+{{ if eq (len $p.Versions) 1 }}
+// {{ goName $p.Name }} returns absolute path to the pinned {{ $p.Name }}-{{ (index $p.Versions 0).Version }} binary.
+func {{ goName $p.Name }}() string {
+	return filepath.Join(GOBIN(), {{ printf "%q" (print $p.Name "-" (index $p.Versions 0).Version) }})
+}
+{{- else }}
+// {{ goName $p.Name }}Array returns absolute paths to all pinned {{ $p.Name }} binaries.
+func {{ goName $p.Name }}Array() []string {
+	return []string{
+{{- range $p.Versions }}
+		filepath.Join(GOBIN(), {{ printf "%q" (print $p.Name "-" .Version) }}),
+{{- end }}
+	}
+}
+{{- end }}
+{{- end }}
+
+// Ensure (re)installs pinned tools that are missing, older than their module or env file{{ if .Binsum }} or not matching their
+// binsum file{{ end }}, using the same build command as Variables.mk. It requires POSIX shell.
+func Ensure(ctx context.Context) error {
+	modDir, err := ModDir()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tools {
+		bin := filepath.Join(GOBIN(), t.bin)
+		ok, err := upToDate(modDir, bin, t)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "(re)installing %v\n", bin)
+		cmd := exec.CommandContext(ctx, "sh", "-c", t.build)
+		cmd.Dir = modDir
+		cmd.Env = append(os.Environ(), "GO="+goCmd(), "BINGO_BIN="+bin)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("(re)installing %v: %w", bin, err)
+		}
+
+		ok, err = binsumOK(modDir, bin, t)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_ = os.Remove(bin)
+			return fmt.Errorf("sha256 of %v does not match the one recorded in %v; either the build is not reproducible or the checksum (or binary) was tampered with", bin, t.binsumFile)
+		}
+	}
+	return nil
+}
+
+func upToDate(modDir, bin string, t tool) (bool, error) {
+	b, err := os.Stat(bin)
+	if err != nil {
+		return false, nil
+	}
+	for _, f := range []string{t.modFile, t.envFile} {
+		if f == "" {
+			continue
+		}
+		s, err := os.Stat(filepath.Join(modDir, f))
+		if err != nil || s.ModTime().After(b.ModTime()) {
+			return false, nil
+		}
+	}
+
+	ok, err := binsumOK(modDir, bin, t)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "sha256 of existing %v does not match the one recorded in %v; reinstalling\n", bin, t.binsumFile)
+	}
+	return ok, nil
+}
+
+// binsumOK returns true if the binary matches sha256 recorded in the binsum file of the tool for the host platform and
+// Go version, or if nothing is recorded.
+func binsumOK(modDir, bin string, t tool) (bool, error) {
+	if t.binsumFile == "" {
+		return true, nil
+	}
+	b, err := os.ReadFile(filepath.Join(modDir, t.binsumFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	key := t.version + " " + goEnvValue("GOHOSTOS") + "/" + goEnvValue("GOHOSTARCH") + " " + goEnvValue("GOVERSION") + " "
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), key) {
+			continue
+		}
+		f, err := os.Open(bin)
+		if err != nil {
+			return false, err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), key)) == hex.EncodeToString(h.Sum(nil)), nil
+	}
+	return true, nil
+}
+`,
+		"taskfile": `# Auto generated binary tasks helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# All tools are designed to be build inside $GOBIN.
+# Below generated tasks ensure that every time a tool under each variable is invoked, the correct version
+# will be used; reinstalling only if needed, thanks to sources and generates checks.
+# For example for {{ with .Example }}{{ .Name }}{{ end }} variable:
+#
+# In your main Taskfile.yml (for non array binaries):
+#
+#includes:
+#  bingo: ./.bingo/Taskfile.yml # Assuming -dir was set to .bingo .
+#
+#tasks:
+#  command:
+#    deps: [bingo:{{ with .Example }}{{ .Name }}{{ end }}]
+#    cmds:
+#      - echo "Running {{ with .Example }}{{ .Name }}{{ end }}"
+#      - '{{ "{{" }}.{{ with .Example }}{{ .EnvVarName }}{{ end }}}} <flags/args..>'
+#
+version: '3'
+
+vars:
+  BINGO_GO:
+    sh: 'echo "${GO:-go}"'
+  BINGO_GOBIN:
+    sh: 'gobin="${GOBIN:-$(${GO:-go} env GOBIN)}"; echo "${gobin:-$(${GO:-go} env GOPATH | cut -d: -f1)/bin}"'
+{{- if .Binsum }}
+  # Binaries are verified against sha256 checksums recorded by 'bingo get' in <tool>.binsum files, also before existing
+  # binaries are used. bingo_binsum_ok succeeds if binary $3 built from version $2 matches sha256 recorded in binsum
+  # file $1 for the host platform and Go version, or if nothing is recorded.
+  BINGO_BINSUM_OK: 'bingo_binsum_ok() { want="$(grep -F "$2 $("${GO:-go}" env GOHOSTOS)/$("${GO:-go}" env GOHOSTARCH) $("${GO:-go}" env GOVERSION) " "$1" 2>/dev/null | cut -d " " -f 4)" && { [ -z "$want" ] || [ "$want" = "$(if command -v sha256sum >/dev/null 2>&1; then sha256sum "$3"; else shasum -a 256 "$3"; fi | cut -d " " -f 1)" ]; }; }'
+{{- end }}
+{{- range $p := .MainPackages }}
+  {{ $p.EnvVarName }}: '{{- range $i, $v := $p.Versions }}{{- if ne $i 0}} {{ end }}{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ $v.Version }}{{- end }}'
+{{- end }}
+
+tasks:
+{{- range $p := .MainPackages }}
+{{- $array := gt (len $p.Versions) 1 }}
+{{- if $array }}
+  '{{ $p.Name }}':
+    desc: (Re)install all pinned versions of {{ $p.Name }} if needed.
+    deps:
+{{- range $p.Versions }}
+      - '{{ $p.Name }}-{{ .Version }}'
+{{- end }}
+{{- end }}
+{{- range $p.Versions }}
+  '{{ if $array }}{{ $p.Name }}-{{ .Version }}{{ else }}{{ $p.Name }}{{ end }}':
+    desc: (Re)install {{ $p.Name }}-{{ .Version }} if needed.
+    dir: '{{ "{{" }}.TASKFILE_DIR}}'
+    sources:
+      - '{{ .ModFile }}'
+{{- with $p.EnvFile }}
+      - '{{ . }}'
+{{- end }}
+    generates:
+      - '{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}'
+{{- if $.Binsum }}
+    status:
+      - '{{ "{{" }}.BINGO_BINSUM_OK}}; [ ! -f "{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" ] || bingo_binsum_ok "{{ binsumFile .ModFile }}" "{{ .Version }}" "{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" || { echo "sha256 of existing {{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; reinstalling" >&2; false; }'
+{{- end }}
+    cmds:
+      # Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
+      - echo "(re)installing {{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}"
+      - |-
+        {{ if $.Binsum }}{{ "{{" }}.BINGO_BINSUM_OK}}; {{ end }}{{ if $.Reproducible }}export {{ range $.ReproducibleEnv }}{{ . }} {{ end }}CGO_ENABLED=0 && {{ end }}{{ with $p.EnvFile }}set -a && . {{ shellQuote (print "./" .) | taskEscape }} && set +a && {{ end }}GOWORK=off {{ range $.GoEnv }}{{ .Name }}={{ shellQuote .Value | taskEscape }} {{ end }}GOOS="$("{{ "{{" }}.BINGO_GO}}" env GOHOSTOS)" GOARCH="$("{{ "{{" }}.BINGO_GO}}" env GOHOSTARCH)" GOARM="$("{{ "{{" }}.BINGO_GO}}" env GOHOSTARM)" {{ range $p.BuildEnvVarsFor .Version }}{{ shellQuoteEnv . | taskEscape }} {{ end }}"{{ "{{" }}.BINGO_GO}}" build {{ if $.Reproducible }}{{ range reproducibleBuildFlags ($p.BuildFlagsFor .Version) }}{{ . }} {{ end }}{{ end }}{{ range $p.BuildFlagsFor .Version }}{{ shellQuoteExpand . | taskEscape }} {{ end }}-mod=mod -modfile="{{ .ModFile }}" -o="{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" "{{ $p.PackagePath }}"
+{{- if $.Binsum }} && { bingo_binsum_ok "{{ binsumFile .ModFile }}" "{{ .Version }}" "{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}" || { echo "sha256 of {{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }} does not match the one recorded in {{ binsumFile .ModFile }}; either the build is not reproducible or the checksum (or binary) was tampered with" >&2; rm -f "{{ "{{" }}.BINGO_GOBIN}}/{{ $p.Name }}-{{ .Version }}"; exit 1; }; }{{ end }}
+{{- end }}
+{{- end }}
 `,
 	}
 )
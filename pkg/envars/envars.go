@@ -21,6 +21,12 @@ func (e EnvSlice) Lookup(k string) (string, bool) {
 	return "", false
 }
 
+// Getenv returns value of the variable or empty string if not set, like os.Getenv.
+func (e EnvSlice) Getenv(k string) string {
+	v, _ := e.Lookup(k)
+	return v
+}
+
 func (e *EnvSlice) Set(kvs ...string) {
 	*e = MergeEnvSlices(*e, kvs...)
 }
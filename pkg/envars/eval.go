@@ -21,15 +21,32 @@ import (
 
 // EvalVariables evaluates dot env file in similar way `bash source` would do and returns all environment variables available at end of the
 // execution of the script.
-// Currently it supports any bash script and can cause side effects.
-// TODO(bwplotka): Walk over syntax and allow list few syntax elements only?
+// It supports any bash script and can cause side effects. Use EvalVariablesRestricted for untrusted input.
+// PWD in the given envSlice sets the working directory of the script, otherwise the current one is used.
 func EvalVariables(ctx context.Context, r io.Reader, envSlice ...string) (ret EnvSlice, _ error) {
+	return evalVariables(ctx, r, false, envSlice)
+}
+
+// EvalVariablesRestricted is like EvalVariables, but it allows only assignments, parameter expansions, quoting and
+// export or declare clauses, so it is safe to evaluate untrusted dot env files. Other syntax (e.g. command
+// substitutions, redirections or command calls) is rejected with an error pointing to its position, before anything is
+// evaluated. Spawning processes and opening files is refused during evaluation too.
+func EvalVariablesRestricted(ctx context.Context, r io.Reader, envSlice ...string) (ret EnvSlice, _ error) {
+	return evalVariables(ctx, r, true, envSlice)
+}
+
+func evalVariables(ctx context.Context, r io.Reader, restricted bool, envSlice []string) (ret EnvSlice, _ error) {
 	const prefix = "[[dotenv.EvalVariables]]:"
 
 	s, err := syntax.NewParser().Parse(r, "")
 	if err != nil {
 		return nil, errors.Wrap(err, "parse")
 	}
+	if restricted {
+		if err := checkRestricted(s); err != nil {
+			return nil, err
+		}
+	}
 
 	vars := listVarNames(s)
 	if len(vars) == 0 {
@@ -60,7 +77,26 @@ func EvalVariables(ctx context.Context, r io.Reader, envSlice ...string) (ret En
 	)
 
 	b := bytes.Buffer{}
-	ru, err := interp.New(interp.StdIO(os.Stdin, &b, &b), interp.Env(expand.ListEnviron(envSlice...)))
+	opts := []interp.RunnerOption{interp.StdIO(os.Stdin, &b, &b), interp.Env(expand.ListEnviron(envSlice...))}
+	if restricted {
+		opts = []interp.RunnerOption{
+			interp.StdIO(nil, &b, &b),
+			interp.Env(expand.ListEnviron(envSlice...)),
+			interp.ExecHandlers(func(interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+				return func(_ context.Context, args []string) error {
+					return errors.Newf("executing %q is not allowed in restricted mode", args[0])
+				}
+			}),
+			interp.OpenHandler(func(_ context.Context, path string, _ int, _ os.FileMode) (io.ReadWriteCloser, error) {
+				return nil, errors.Newf("opening %q is not allowed in restricted mode", path)
+			}),
+		}
+	}
+	// Interpreter overrides PWD with its directory.
+	if pwd, ok := EnvSlice(envSlice).Lookup("PWD"); ok {
+		opts = append(opts, interp.Dir(pwd))
+	}
+	ru, err := interp.New(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +140,62 @@ func listVarNames(ast *syntax.File) (vars []string) {
 	return vars
 }
 
+// checkRestricted returns error for the first syntax element that is not allowed in restricted mode.
+func checkRestricted(ast *syntax.File) (err error) {
+	notAllowed := func(n syntax.Node, what string) bool {
+		err = errors.Newf("%v: %v is not allowed in restricted mode", n.Pos(), what)
+		return false
+	}
+	syntax.Walk(ast, func(node syntax.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case nil, *syntax.File, *syntax.Comment, *syntax.Assign, *syntax.ArrayExpr, *syntax.ArrayElem,
+			*syntax.Word, *syntax.Lit, *syntax.SglQuoted, *syntax.DblQuoted, *syntax.ParamExp:
+			return true
+		case *syntax.Stmt:
+			switch {
+			case len(n.Redirs) > 0:
+				return notAllowed(n.Redirs[0], "redirection")
+			case n.Background || n.Coprocess:
+				return notAllowed(n, "background command")
+			case n.Negated:
+				return notAllowed(n, "negation")
+			}
+			return true
+		case *syntax.CallExpr:
+			if len(n.Args) > 0 {
+				return notAllowed(n, fmt.Sprintf("command call (%v)", wordString(n.Args[0])))
+			}
+			return true
+		case *syntax.DeclClause:
+			if n.Variant.Value != "export" && n.Variant.Value != "declare" {
+				return notAllowed(n, n.Variant.Value)
+			}
+			return true
+		case *syntax.CmdSubst:
+			return notAllowed(n, "command substitution")
+		case *syntax.ProcSubst:
+			return notAllowed(n, "process substitution")
+		case *syntax.Redirect:
+			return notAllowed(n, "redirection")
+		default:
+			return notAllowed(n, strings.TrimPrefix(fmt.Sprintf("%T", n), "*syntax."))
+		}
+	})
+	return err
+}
+
+func wordString(w *syntax.Word) string {
+	if lit := w.Lit(); lit != "" {
+		return lit
+	}
+	b := strings.Builder{}
+	_ = syntax.NewPrinter().Print(&b, w)
+	return b.String()
+}
+
 func trimDeclStmts(ast *syntax.File) {
 	for _, s := range ast.Stmts {
 		syntax.Walk(s, func(node syntax.Node) bool {
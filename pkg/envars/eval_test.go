@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/efficientgo/core/testutil"
@@ -67,7 +68,56 @@ func TestEval(t *testing.T) {
 		testutil.Ok(t, err)
 		testutil.Equals(t, EnvSlice{"GOBIN=/home/something/bin", "PROXY=/home/something/bin/proxy-v0.10.0", "X=/home/something/bin/proxy-v0.12.0"}, e)
 	})
+	t.Run("PWD", func(t *testing.T) {
+		dir := t.TempDir()
 
+		e, err := EvalVariables(context.TODO(), strings.NewReader(`CGO_CFLAGS="-I${PWD}/include"`), "PWD="+dir)
+		testutil.Ok(t, err)
+		testutil.Equals(t, EnvSlice{"CGO_CFLAGS=-I" + dir + "/include"}, e)
+	})
+}
+
+func TestEvalRestricted(t *testing.T) {
+	t.Run("simple.env", func(t *testing.T) {
+		b, err := os.ReadFile("testdata/simple.env")
+		testutil.Ok(t, err)
+
+		e, err := EvalVariablesRestricted(context.TODO(), bytes.NewReader(b))
+		testutil.Ok(t, err)
+		testutil.Equals(t, EnvSlice{
+			"VAR1=with space 124", "VAR2=with space 124-yolo", "VAR3=with\\n\\nnewline",
+		}, e)
+	})
+	t.Run("assignments, expansions and declarations", func(t *testing.T) {
+		e, err := EvalVariablesRestricted(context.TODO(), strings.NewReader(`# Comment.
+GOBIN=${GOBIN:-/default/bin}
+declare TOOL='${GOBIN}'
+export PROXY="${GOBIN}/proxy-v0.10.0" X=${HOME}
+`), "GOBIN=/home/something/bin", "HOME=/home/something")
+		testutil.Ok(t, err)
+		testutil.Equals(t, EnvSlice{"GOBIN=/home/something/bin", "TOOL=${GOBIN}", "PROXY=/home/something/bin/proxy-v0.10.0", "X=/home/something"}, e)
+	})
+	for _, tcase := range []struct {
+		script   string
+		expected string
+	}{
+		{script: "GOBIN=${GOBIN:=$(go env GOBIN)}\n", expected: "1:16: command substitution is not allowed in restricted mode"},
+		{script: "A=1\nB=`id`\n", expected: "2:3: command substitution is not allowed in restricted mode"},
+		{script: "A=1\ncurl http://example.com\n", expected: "2:1: command call (curl) is not allowed in restricted mode"},
+		{script: "A=1 echo yolo\n", expected: "1:1: command call (echo) is not allowed in restricted mode"},
+		{script: "A=1 > /tmp/file\n", expected: "1:5: redirection is not allowed in restricted mode"},
+		{script: "A=<(ls)\n", expected: "1:3: process substitution is not allowed in restricted mode"},
+		{script: "if [ -z \"$A\" ]; then A=1; fi\n", expected: "1:1: IfClause is not allowed in restricted mode"},
+		{script: "A=1 &\n", expected: "1:1: background command is not allowed in restricted mode"},
+		{script: "readonly A=1\n", expected: "1:1: readonly is not allowed in restricted mode"},
+		{script: "A=$((1+2))\n", expected: "1:3: ArithmExp is not allowed in restricted mode"},
+	} {
+		t.Run(tcase.script, func(t *testing.T) {
+			_, err := EvalVariablesRestricted(context.TODO(), strings.NewReader(tcase.script), "PATH="+os.Getenv("PATH"))
+			testutil.NotOk(t, err)
+			testutil.Equals(t, tcase.expected, err.Error())
+		})
+	}
 }
 
 func TestMergeEnvSlices(t *testing.T) {
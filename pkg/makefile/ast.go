@@ -0,0 +1,212 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package makefile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position of the node in the makefile.
+type Pos struct {
+	// File is a path of the makefile. Empty for input that was not read from a file.
+	File string
+	// Line and Column start from 1. Column counts bytes.
+	Line, Column int
+}
+
+func (p Pos) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%v:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Node interface.
+type Node interface {
+	// Pos returns position of the first line of the node.
+	Pos() Pos
+	// Lines returns indexes (starting from 0) of the lines related to the node.
+	Lines() []int
+}
+
+type node struct {
+	pos   Pos
+	lines []int
+}
+
+func (n node) Pos() Pos {
+	return n.pos
+}
+
+func (n node) Lines() []int {
+	return n.lines
+}
+
+// Comment node. Comments directly above the rule and "## <comment>" at the end of the rule line document its Target.
+type Comment struct {
+	node
+
+	Target string
+	Value  string
+	// Inline is true for "## <comment>" at the end of the rule line.
+	Inline bool
+	// Default is true if the Target is the default goal. Set only by ParseRecursive and ParseFileRecursive.
+	Default bool
+}
+
+// Include node, for include, -include and sinclude directives.
+type Include struct {
+	node
+
+	Value string
+	// Optional is true for -include and sinclude directives, which ignore missing files.
+	Optional bool
+}
+
+// Files returns names of the included files.
+func (i Include) Files() []string {
+	return fields(i.Value)
+}
+
+// Rule node (e.g. "lint: $(GOLANGCI_LINT) | fmt").
+type Rule struct {
+	node
+
+	Targets []string
+	// DoubleColon is true for "::" rules.
+	DoubleColon bool
+	Prereqs     []string
+	// OrderOnly are prerequisites after "|".
+	OrderOnly []string
+	Recipe    []Recipe
+	// Refs are names of variables referenced in targets and prerequisites.
+	Refs []string
+}
+
+// Recipe node is a single command line of a rule.
+type Recipe struct {
+	node
+
+	// Value is a command without the leading tab. Continued lines are separated by backslash-newline.
+	Value string
+	// Refs are names of variables referenced in the command.
+	Refs []string
+}
+
+// Assignment node is a variable assignment (e.g. "GO ?= go"), also multi-line (define ... endef).
+type Assignment struct {
+	node
+
+	Name string
+	// Op is one of "=", ":=", "::=", "?=", "+=" or "!=".
+	Op    string
+	Value string
+	// Export and Override are true for assignments prefixed with export and override.
+	Export, Override bool
+	// Targets are set for target-specific variables (e.g. "lint: GOFLAGS = -mod=mod").
+	Targets []string
+	// Refs are names of variables referenced in the value.
+	Refs []string
+}
+
+// Conditional node is ifeq, ifneq, ifdef or ifndef block. Chained "else <directive>" is a single Conditional in Else.
+type Conditional struct {
+	node
+
+	Directive string
+	Condition string
+	Then      []Node
+	Else      []Node
+	// Refs are names of variables referenced in the condition.
+	Refs []string
+}
+
+// Walk calls fn for the given nodes in order, descending into both branches of conditionals. It stops when fn returns
+// false.
+func Walk(nodes []Node, fn func(Node) bool) bool {
+	for _, n := range nodes {
+		if !fn(n) {
+			return false
+		}
+		if c, ok := n.(Conditional); ok {
+			if !Walk(c.Then, fn) || !Walk(c.Else, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// VariableRefs returns names of variables referenced in the given makefile line (e.g. "FOO" for "$(FOO)" or "${FOO}"),
+// in order of appearance. Function calls (e.g. "$(shell ...)") are not references, but their arguments are inspected.
+func VariableRefs(line string) []string {
+	var refs []string
+	for i := 0; i < len(line)-1; i++ {
+		if line[i] != '$' {
+			continue
+		}
+		if line[i+1] == '$' {
+			// Escaped dollar.
+			i++
+			continue
+		}
+		if line[i+1] != '(' && line[i+1] != '{' {
+			continue
+		}
+		end := i + 2
+		for end < len(line) && isVariableNameChar(line[end]) {
+			end++
+		}
+		if end == i+2 || end == len(line) {
+			continue
+		}
+		// Substitution references (e.g. "$(FOO:.c=.o)") are references too.
+		if c := line[end]; c == ')' || c == '}' || c == ':' {
+			refs = append(refs, line[i+2:end])
+		}
+	}
+	return refs
+}
+
+func isVariableNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// indexTopLevel returns index of the first occurrence of any of the given bytes outside of variable references and
+// function calls, or -1.
+func indexTopLevel(s, chars string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == '{'):
+			depth++
+			i++
+		case depth > 0 && (s[i] == '(' || s[i] == '{'):
+			depth++
+		case depth > 0 && (s[i] == ')' || s[i] == '}'):
+			depth--
+		case depth == 0 && strings.IndexByte(chars, s[i]) >= 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// fields splits s around white spaces outside of variable references and function calls.
+func fields(s string) []string {
+	var ret []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return ret
+		}
+		i := indexTopLevel(s, " \t")
+		if i < 0 {
+			return append(ret, s)
+		}
+		ret = append(ret, s[:i])
+		s = s[i:]
+	}
+}
@@ -4,89 +4,422 @@
 package makefile
 
 import (
+	"bufio"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/efficientgo/core/errors"
 )
 
-// Copied from https://github.com/tj/mmake/blob/b15229aac1a8ea3f0875f064a0864f7250bd7850/parser & improved.
+// Originally copied from https://github.com/tj/mmake/blob/b15229aac1a8ea3f0875f064a0864f7250bd7850/parser & improved.
 
-// Node interface.
-type Node interface {
-	Lines() []int
+// Parser is a streaming Makefile parser. It does not evaluate the makefile (variables, functions and conditionals are
+// kept as they are written), but it understands its structure: rules with recipes, variable assignments, includes,
+// conditional blocks and comments documenting targets.
+type Parser struct {
+	r    *bufio.Reader
+	file string
+	// i is an index of the next physical line.
+	i    int
+	done bool
+
+	commentBuf []string
+	commentPos Pos
+
+	// rule is a rule which recipe is still being parsed.
+	rule *Rule
+	// ruleDepth is a number of conditional frames open when the rule started. Rule stays open across conditional
+	// directives nested in its recipe context, so recipe lines in conditional branches are part of its recipe.
+	ruleDepth int
+	// deferred are nodes parsed within the recipe context, emitted after the rule.
+	deferred []Node
+	frames   []*frame
+	queue    []Node
+}
+
+type frame struct {
+	cond   *Conditional
+	inElse bool
+	// chained is true for conditionals started by "else <directive>", closed by the same endif as their parent.
+	chained bool
 }
 
-type node struct {
-	lines []int
+// line is a logical line, with continued physical lines joined by backslash-newline.
+type line struct {
+	text string
+	// i is an index of the first physical line.
+	i int
 }
 
-func (n node) Lines() []int {
-	return n.lines
+// NewParser returns parser reading the makefile from r. File is used only in node positions and can be empty.
+func NewParser(r io.Reader, file string) *Parser {
+	return &Parser{r: bufio.NewReader(r), file: file}
 }
 
-// Comment node.
-type Comment struct {
-	node
+// Parse the given input reader.
+func (p *Parser) Parse(r io.Reader) ([]Node, error) {
+	p.r = bufio.NewReader(r)
 
-	Target  string
-	Value   string
-	Default bool
+	var nodes []Node
+	for {
+		n, err := p.Next()
+		if err == io.EOF {
+			return nodes, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing")
+		}
+		nodes = append(nodes, n)
+	}
 }
 
-// Include node.
-type Include struct {
-	node
+// Next returns next top level node or io.EOF if there are no more nodes.
+func (p *Parser) Next() (Node, error) {
+	for len(p.queue) == 0 {
+		if p.done {
+			return nil, io.EOF
+		}
+		if err := p.step(); err != nil {
+			return nil, err
+		}
+	}
+	n := p.queue[0]
+	p.queue = p.queue[1:]
+	return n, nil
+}
 
-	Value string
+func (p *Parser) pos(i, column int) Pos {
+	return Pos{File: p.file, Line: i + 1, Column: column}
 }
 
-// Parser is a quick-n-dirty Makefile "parser", not
-// really, just comments and a few directives, but
-// you'll forgive me.
-type Parser struct {
-	i          int
-	lines      []string
-	nodeBuf    node
-	commentBuf []string
-	target     string
-	nodes      []Node
+// readLine returns next logical line.
+func (p *Parser) readLine() (line, bool, error) {
+	l := line{i: p.i}
+	for {
+		s, err := p.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return line{}, false, errors.Wrap(err, "read")
+		}
+		if err == io.EOF && s == "" {
+			if p.i == l.i {
+				return line{}, false, nil
+			}
+			// Continuation at the end of the file.
+			return l, true, nil
+		}
+		p.i++
+
+		s = strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+		if p.i-1 > l.i {
+			l.text += "\n"
+		}
+		l.text += s
+		if !isContinued(s) || err == io.EOF {
+			return l, true, nil
+		}
+	}
 }
 
-// Parse the given input reader.
-// TODO(bwplotka): Streaming version would be nice.
-func (p *Parser) Parse(r io.Reader) ([]Node, error) {
-	b, err := io.ReadAll(r)
+// isContinued returns true if the line ends with odd number of backslashes.
+func isContinued(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+var continuationRe = regexp.MustCompile(`[ \t]*\\\n[ \t]*`)
+
+// joinContinuations replaces backslash-newline and surrounding white spaces with a single space, like make does for
+// non-recipe lines.
+func joinContinuations(s string) string {
+	return continuationRe.ReplaceAllString(s, " ")
+}
+
+// splitComment splits trailing comment, not escaped with backslash, from the line. Comment is returned without the
+// leading '#'.
+func splitComment(s string) (text, comment string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] != '\\') {
+			return strings.TrimRight(s[:i], " \t"), s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+// conditionalDirectives start conditional blocks.
+var conditionalDirectives = []string{"ifeq", "ifneq", "ifdef", "ifndef"}
+
+// includeDirectives are directives that include other makefiles.
+var includeDirectives = []string{"include", "-include", "sinclude"}
+
+func firstWord(s string) (word, rest string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeft(s[i:], " \t")
+}
+
+func contains(words []string, w string) bool {
+	for _, x := range words {
+		if x == w {
+			return true
+		}
+	}
+	return false
+}
+
+// step parses next logical line.
+func (p *Parser) step() error {
+	l, ok, err := p.readLine()
 	if err != nil {
-		return nil, errors.Wrap(err, "reading")
+		return err
+	}
+	if !ok {
+		p.flushRule()
+		p.pushComment()
+		if len(p.frames) > 0 {
+			return errors.Newf("%v: missing endif", p.frames[0].cond.pos)
+		}
+		p.done = true
+		return nil
 	}
 
-	p.lines = strings.Split(string(b), "\n")
+	if strings.HasPrefix(l.text, "\t") && p.rule != nil {
+		v := strings.ReplaceAll(l.text[1:], "\\\n\t", "\\\n")
+		p.rule.Recipe = append(p.rule.Recipe, Recipe{
+			node:  node{pos: p.pos(l.i, 2), lines: []int{l.i}},
+			Value: v,
+			Refs:  VariableRefs(v),
+		})
+		return nil
+	}
 
-	if err := p.parse(); err != nil {
-		return nil, errors.Wrap(err, "parsing")
+	trimmed := strings.TrimLeft(l.text, " \t")
+	column := len(l.text) - len(trimmed) + 1
+	switch {
+	case trimmed == "":
+		p.pushComment()
+		return nil
+	case trimmed[0] == '#':
+		p.bufferComment(trimmed, p.pos(l.i, column))
+		return nil
 	}
 
-	return p.nodes, nil
+	text, comment := splitComment(joinContinuations(trimmed))
+	n := node{pos: p.pos(l.i, column), lines: []int{l.i}}
+	word, rest := firstWord(text)
+	switch {
+	case contains(conditionalDirectives, word):
+		p.frames = append(p.frames, &frame{cond: newConditional(n, word, rest)})
+	case word == "else":
+		if len(p.frames) == 0 {
+			return errors.Newf("%v: else without if", n.pos)
+		}
+		f := p.frames[len(p.frames)-1]
+		if f.inElse {
+			return errors.Newf("%v: only one else per conditional", n.pos)
+		}
+		if p.ruleDepth >= len(p.frames) {
+			// Rule started in this branch.
+			p.flushRule()
+		}
+		f.inElse = true
+		if rest != "" {
+			directive, cond := firstWord(rest)
+			if !contains(conditionalDirectives, directive) {
+				return errors.Newf("%v: unexpected text after else: %v", n.pos, rest)
+			}
+			p.frames = append(p.frames, &frame{cond: newConditional(n, directive, cond), chained: true})
+		}
+	case word == "endif":
+		if len(p.frames) == 0 {
+			return errors.Newf("%v: endif without if", n.pos)
+		}
+		for {
+			if p.ruleDepth >= len(p.frames) {
+				// Rule started in this conditional.
+				p.flushRule()
+			}
+			f := p.frames[len(p.frames)-1]
+			p.frames = p.frames[:len(p.frames)-1]
+			p.emit(*f.cond)
+			if !f.chained {
+				break
+			}
+		}
+	case word == "define":
+		p.flushRule()
+		return p.parseDefine(Assignment{node: n}, rest)
+	case contains(includeDirectives, word):
+		p.flushRule()
+		p.pushComment()
+		p.emit(Include{node: n, Value: rest, Optional: word != "include"})
+	case word == "export" || word == "override" || word == "private" || word == "unexport":
+		p.flushRule()
+		a := Assignment{node: n}
+		for ; contains(assignmentModifiers, word); word, rest = firstWord(rest) {
+			a.Export = a.Export || word == "export"
+			a.Override = a.Override || word == "override"
+		}
+		if word == "define" {
+			return p.parseDefine(a, rest)
+		}
+		parsed, ok := parseAssignment(text)
+		if !ok {
+			// Directive without assignment (e.g. "export GOBIN").
+			return nil
+		}
+		parsed.node = n
+		p.pushAssignment(parsed)
+	default:
+		if a, ok := parseAssignment(text); ok {
+			p.flushRule()
+			a.node = n
+			p.pushAssignment(a)
+			return nil
+		}
+		if indexTopLevel(text, ":") >= 0 {
+			p.flushRule()
+			p.parseRule(n, text, comment)
+			return nil
+		}
+		// Function calls (e.g. $(eval ...)) and other directives (e.g. vpath).
+		p.flushRule()
+	}
+	return nil
 }
 
-// Peek at the next line.
-func (p *Parser) peek() string {
-	return p.lines[p.i]
+func newConditional(n node, directive, condition string) *Conditional {
+	return &Conditional{node: n, Directive: directive, Condition: condition, Refs: VariableRefs(condition)}
 }
 
-// Advance the next line.
-func (p *Parser) advance() string {
-	s := p.lines[p.i]
-	p.i++
-	return s
+// parseDefine parses multi-line variable definition, until matching endef.
+func (p *Parser) parseDefine(a Assignment, rest string) error {
+	a.Name, a.Op = firstWord(strings.TrimSpace(rest))
+	if a.Op == "" {
+		a.Op = "="
+	}
+
+	var value []string
+	nested := 0
+	for {
+		l, ok, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Newf("%v: missing endef", a.pos)
+		}
+		word, _ := firstWord(strings.TrimLeft(l.text, " \t"))
+		switch word {
+		case "define":
+			nested++
+		case "endef":
+			if nested == 0 {
+				a.Value = strings.Join(value, "\n")
+				p.pushAssignment(a)
+				return nil
+			}
+			nested--
+		}
+		value = append(value, l.text)
+	}
+}
+
+// parseRule parses the rule line, which can also define target specific variable.
+func (p *Parser) parseRule(n node, text, comment string) {
+	colon := indexTopLevel(text, ":")
+	r := Rule{node: n, Targets: fields(text[:colon]), Refs: VariableRefs(text)}
+	rest := text[colon+1:]
+	if strings.HasPrefix(rest, ":") && !strings.HasPrefix(rest, ":=") {
+		r.DoubleColon = true
+		rest = rest[1:]
+	}
+
+	if a, ok := parseAssignment(strings.TrimSpace(rest)); ok {
+		a.node = n
+		a.Targets = r.Targets
+		p.emit(a)
+		return
+	}
+
+	var inline string
+	if i := indexTopLevel(rest, ";"); i >= 0 {
+		inline = strings.TrimSpace(rest[i+1:])
+		rest = rest[:i]
+	}
+	if i := indexTopLevel(rest, "|"); i >= 0 {
+		r.OrderOnly = fields(rest[i+1:])
+		rest = rest[:i]
+	}
+	r.Prereqs = fields(rest)
+	if inline != "" {
+		r.Recipe = append(r.Recipe, Recipe{node: n, Value: inline, Refs: VariableRefs(inline)})
+	}
+
+	// Special targets (e.g. .PHONY) are not documented, so comments above them belong to the next rule.
+	if len(r.Targets) == 0 || !strings.HasPrefix(r.Targets[0], ".") {
+		target := strings.TrimSpace(text[:colon])
+		p.target(target, n.lines)
+		if strings.HasPrefix(comment, "#") {
+			p.emit(Comment{node: n, Target: target, Value: strings.TrimSpace(comment[1:]), Inline: true})
+		}
+	}
+	p.rule = &r
+	p.ruleDepth = len(p.frames)
+}
+
+// emit adds the node to the output, after the rule with the recipe being parsed, if any and if the node is in the
+// same conditional branch as the rule.
+func (p *Parser) emit(n Node) {
+	if p.rule != nil && p.ruleDepth == len(p.frames) {
+		p.deferred = append(p.deferred, n)
+		return
+	}
+	p.out(n)
+}
+
+// out adds the node to the current conditional branch or to the top level nodes.
+func (p *Parser) out(n Node) {
+	p.outAt(len(p.frames), n)
+}
+
+// outAt adds the node to the branch of the conditional frame at the given depth or to the top level nodes for 0.
+func (p *Parser) outAt(depth int, n Node) {
+	if depth == 0 {
+		p.queue = append(p.queue, n)
+		return
+	}
+	f := p.frames[depth-1]
+	if f.inElse {
+		f.cond.Else = append(f.cond.Else, n)
+		return
+	}
+	f.cond.Then = append(f.cond.Then, n)
+}
+
+// flushRule emits the rule with the recipe being parsed and nodes deferred after it.
+func (p *Parser) flushRule() {
+	if p.rule == nil {
+		return
+	}
+	p.outAt(p.ruleDepth, *p.rule)
+	p.rule = nil
+	for _, n := range p.deferred {
+		p.outAt(p.ruleDepth, n)
+	}
+	p.deferred = nil
 }
 
 // Buffer comment.
-func (p *Parser) bufferComment() {
-	s := p.advance()[1:]
+func (p *Parser) bufferComment(line string, pos Pos) {
+	s := line[1:]
 
 	if len(s) > 0 {
 		if s[0] == '-' {
@@ -98,61 +431,77 @@ func (p *Parser) bufferComment() {
 			s = s[1:]
 		}
 	}
+	if len(p.commentBuf) == 0 {
+		p.commentPos = pos
+	}
 	p.commentBuf = append(p.commentBuf, s)
 }
 
-// Push comment node.
+// Push comment node, not documenting any target.
 func (p *Parser) pushComment() {
+	p.target("", nil)
+}
+
+// target pushes buffered comment as a documentation of the given target.
+func (p *Parser) target(target string, lines []int) {
 	if len(p.commentBuf) == 0 {
 		return
 	}
 
 	s := strings.Join(p.commentBuf, "\n")
-
-	p.nodes = append(p.nodes, Comment{
-		node:   p.nodeBuf,
-		Target: p.target,
+	p.emit(Comment{
+		node:   node{pos: p.commentPos, lines: lines},
+		Target: target,
 		Value:  strings.Trim(s, "\n"),
 	})
-	p.nodeBuf = node{}
 	p.commentBuf = nil
-	p.target = ""
 }
 
-// Push include node.
-func (p *Parser) pushInclude() {
-	s := strings.Trim(strings.Replace(p.advance(), "include ", "", 1), " ")
-	p.nodeBuf.lines = append(p.nodeBuf.lines, p.i-1)
-	p.nodes = append(p.nodes, Include{
-		node:  p.nodeBuf,
-		Value: s,
-	})
-	p.nodeBuf = node{}
+func (p *Parser) pushAssignment(a Assignment) {
+	// Comment above the variable is not a target documentation.
+	p.pushComment()
+	a.Refs = VariableRefs(a.Value)
+	p.emit(a)
 }
 
-// Parse the input.
-func (p *Parser) parse() error {
-	for {
-		switch {
-		case p.i == len(p.lines)-1:
-			return nil
-		case strings.HasPrefix(p.peek(), ".PHONY"):
-			p.advance()
-		case len(p.peek()) == 0:
-			p.pushComment()
-			p.advance()
-		case p.peek()[0] == '#':
-			p.bufferComment()
-		case strings.HasPrefix(p.peek(), "include "):
-			p.pushInclude()
-		case strings.ContainsRune(p.peek(), ':'):
-			p.target = strings.Split(p.advance(), ":")[0]
-			p.nodeBuf.lines = append(p.nodeBuf.lines, p.i-1)
-			p.pushComment()
-		default:
-			p.advance()
+// assignmentModifiers can prefix variable assignments.
+var assignmentModifiers = []string{"export", "override", "private", "unexport"}
+
+// assignmentOps are variable assignment operators, longest first.
+var assignmentOps = []string{"::=", ":=", "?=", "+=", "!=", "="}
+
+// parseAssignment parses the line as a variable assignment, with optional export, override or private modifiers.
+func parseAssignment(line string) (a Assignment, ok bool) {
+	eq := indexTopLevel(line, "=")
+	if eq < 0 {
+		return Assignment{}, false
+	}
+	for _, op := range assignmentOps {
+		start := eq + 1 - len(op)
+		if start < 0 || line[start:eq+1] != op {
+			continue
+		}
+		name := strings.TrimSpace(line[:start])
+		for {
+			word, rest := firstWord(name)
+			switch word {
+			case "export":
+				a.Export = true
+			case "override":
+				a.Override = true
+			case "private":
+			default:
+				// Target specific variables (e.g. "target: VAR = value") are part of a rule.
+				if name == "" || strings.ContainsAny(name, ": \t") {
+					return Assignment{}, false
+				}
+				a.Name, a.Op, a.Value = name, op, strings.TrimSpace(line[eq+1:])
+				return a, true
+			}
+			name = rest
 		}
 	}
+	return Assignment{}, false
 }
 
 // Parse the given input.
@@ -163,59 +512,126 @@ func Parse(r io.Reader) ([]Node, error) {
 // ParseRecursive parses the given input recursively
 // relative to the given dir such as /usr/local/include.
 func ParseRecursive(r io.Reader, dir string) ([]Node, error) {
-	nodes, err := parseRecursiveHelper(r, dir)
-
-	for i := range nodes {
-		defaultComment, ok := nodes[i].(Comment)
-		if !ok {
-			continue
-		}
-
-		defaultComment.Default = true
-		nodes[i] = Comment{
-			Target:  defaultComment.Target,
-			Value:   defaultComment.Value,
-			Default: true,
-		}
-		break
+	nodes, err := Parse(r)
+	if err != nil {
+		return nil, err
 	}
-
-	return nodes, err
+	nodes, err = parseIncludes(nodes, dir, nil)
+	return markDefault(nodes), err
 }
 
-func parseRecursiveHelper(r io.Reader, dir string) ([]Node, error) {
-	nodes, err := Parse(r)
+// ParseFileRecursive parses the given makefile recursively, relative to its directory.
+func ParseFileRecursive(file string) ([]Node, error) {
+	nodes, err := parseFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%q", file)
+	}
+	nodes, err = parseIncludes(nodes, filepath.Dir(file), []string{file})
+	return markDefault(nodes), err
+}
 
+func parseFile(file string) (_ []Node, err error) {
+	f, err := os.Open(file)
 	if err != nil {
-		return nil, errors.Wrap(err, "parsing")
+		return nil, err
 	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	return (&Parser{file: file}).Parse(f)
+}
 
-	otherNodes := []Node{}
+// DefaultGoal returns the target make builds when run without arguments: value of .DEFAULT_GOAL or the first target
+// that is not special (e.g. .PHONY) nor a pattern.
+func DefaultGoal(nodes []Node) string {
+	var goal string
 	for _, n := range nodes {
-		otherNodes = append(otherNodes, n)
-
-		inc, ok := n.(Include)
-
+		if a, ok := n.(Assignment); ok && a.Name == ".DEFAULT_GOAL" && a.Targets == nil {
+			goal = a.Value
+		}
+	}
+	if goal != "" {
+		return goal
+	}
+	for _, n := range nodes {
+		r, ok := n.(Rule)
 		if !ok {
 			continue
 		}
-
-		path := filepath.Join(dir, inc.Value)
-		f, err := os.Open(path)
-		if err != nil {
-			return nil, errors.Wrapf(err, "opening %q", path)
+		for _, t := range r.Targets {
+			if !strings.HasPrefix(t, ".") && !strings.Contains(t, "%") {
+				return t
+			}
 		}
+	}
+	return ""
+}
 
-		more, err := parseRecursiveHelper(f, dir)
-
-		if err != nil {
-			return nil, errors.Wrapf(err, "parsing %q", path)
+// markDefault marks comments documenting the default goal.
+func markDefault(nodes []Node) []Node {
+	goal := DefaultGoal(nodes)
+	if goal == "" {
+		return nodes
+	}
+	for i := range nodes {
+		defaultComment, ok := nodes[i].(Comment)
+		if !ok || !contains(fields(defaultComment.Target), goal) {
+			continue
 		}
 
-		otherNodes = append(otherNodes, more...)
-
-		f.Close()
+		defaultComment.Default = true
+		nodes[i] = defaultComment
 	}
+	return nodes
+}
 
+// parseIncludes returns nodes with nodes of included files added after each include, also in conditionals (both
+// branches, as conditions are not evaluated). Includes using variables are not followed.
+func parseIncludes(nodes []Node, dir string, parents []string) ([]Node, error) {
+	otherNodes := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case Conditional:
+			var err error
+			if n.Then, err = parseIncludes(n.Then, dir, parents); err != nil {
+				return nil, err
+			}
+			if n.Else, err = parseIncludes(n.Else, dir, parents); err != nil {
+				return nil, err
+			}
+			otherNodes = append(otherNodes, n)
+		case Include:
+			otherNodes = append(otherNodes, n)
+			for _, f := range n.Files() {
+				if strings.Contains(f, "$") {
+					continue
+				}
+
+				path := f
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, f)
+				}
+				if contains(parents, path) {
+					return nil, errors.Newf("%v: include cycle: %v", n.pos, strings.Join(append(parents, path), " -> "))
+				}
+				more, err := parseFile(path)
+				if err != nil {
+					if n.Optional && os.IsNotExist(err) {
+						continue
+					}
+					return nil, errors.Wrapf(err, "parsing %q", path)
+				}
+				more, err = parseIncludes(more, dir, append(parents, path))
+				if err != nil {
+					return nil, err
+				}
+				otherNodes = append(otherNodes, more...)
+			}
+		default:
+			otherNodes = append(otherNodes, n)
+		}
+	}
 	return otherNodes, nil
 }
@@ -5,7 +5,13 @@ package makefile
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
 )
 
 func ExampleParser_Parse_withComments() {
@@ -55,12 +61,22 @@ dummy:
 	}
 
 	// Output:
-	// makefile.Include{node:makefile.node{lines:[]int{1}}, Value:"github.com/tj/foo"}
-	// makefile.Comment{node:makefile.node{lines:[]int{7}}, Target:"start", Value:"Stuff here:\n\n   :)\n\nStart the dev server.", Default:false}
-	// makefile.Comment{node:makefile.node{lines:[]int{8, 11}}, Target:"api", Value:"Start the API server.", Default:false}
-	// makefile.Comment{node:makefile.node{lines:[]int{15}}, Target:"deps", Value:"Display dependency graph.", Default:false}
-	// makefile.Comment{node:makefile.node{lines:[]int{24}}, Target:"size", Value:"Display size of dependencies.\n\n- foo\n- bar\n- baz", Default:false}
-	// makefile.Comment{node:makefile.node{lines:[]int{32}}, Target:"dummy", Value:"Just a comment.\nJust another comment.", Default:false}
+	// makefile.Include{node:makefile.node{pos:makefile.Pos{File:"", Line:2, Column:1}, lines:[]int{1}}, Value:"github.com/tj/foo", Optional:false}
+	// makefile.Comment{node:makefile.node{pos:makefile.Pos{File:"", Line:3, Column:1}, lines:[]int{7}}, Target:"start", Value:"Stuff here:\n\n   :)\n\nStart the dev server.", Inline:false, Default:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:8, Column:1}, lines:[]int{7}}, Targets:[]string{"start"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:9, Column:2}, lines:[]int{8}}, Value:"@gopherjs -m -v serve --http :3000 github.com/tj/docs/client", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:10, Column:1}, lines:[]int{9}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"start"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Comment{node:makefile.node{pos:makefile.Pos{File:"", Line:11, Column:1}, lines:[]int{11}}, Target:"api", Value:"Start the API server.", Inline:false, Default:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:12, Column:1}, lines:[]int{11}}, Targets:[]string{"api"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:13, Column:2}, lines:[]int{12}}, Value:"@go run server/cmd/api/api.go", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:14, Column:1}, lines:[]int{13}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"api"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Comment{node:makefile.node{pos:makefile.Pos{File:"", Line:15, Column:1}, lines:[]int{15}}, Target:"deps", Value:"Display dependency graph.", Inline:false, Default:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:16, Column:1}, lines:[]int{15}}, Targets:[]string{"deps"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:17, Column:2}, lines:[]int{16}}, Value:"@godepgraph github.com/tj/docs/client | dot -Tsvg | browser", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:18, Column:1}, lines:[]int{17}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"deps"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Comment{node:makefile.node{pos:makefile.Pos{File:"", Line:19, Column:1}, lines:[]int{24}}, Target:"size", Value:"Display size of dependencies.\n\n- foo\n- bar\n- baz", Inline:false, Default:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:25, Column:1}, lines:[]int{24}}, Targets:[]string{"size"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:26, Column:2}, lines:[]int{25}}, Value:"@gopherjs build client/*.go -m -o /tmp/out.js", Refs:[]string(nil)}, makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:27, Column:2}, lines:[]int{26}}, Value:"@du -h /tmp/out.js", Refs:[]string(nil)}, makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:28, Column:2}, lines:[]int{27}}, Value:"@gopher-count /tmp/out.js | sort -nr", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:29, Column:1}, lines:[]int{28}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"size"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:30, Column:1}, lines:[]int{29}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"dummy"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Comment{node:makefile.node{pos:makefile.Pos{File:"", Line:31, Column:1}, lines:[]int{32}}, Target:"dummy", Value:"Just a comment.\nJust another comment.", Inline:false, Default:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:33, Column:1}, lines:[]int{32}}, Targets:[]string{"dummy"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:34, Column:2}, lines:[]int{33}}, Value:"@ls", Refs:[]string(nil)}}, Refs:[]string(nil)}
 }
 
 func ExampleParser_Parse_withoutComments() {
@@ -89,7 +105,232 @@ deps:
 	}
 
 	// Output:
-	// makefile.Include{node:makefile.node{lines:[]int{1}}, Value:"github.com/tj/foo"}
-	// makefile.Include{node:makefile.node{lines:[]int{2}}, Value:"github.com/tj/bar"}
-	// makefile.Include{node:makefile.node{lines:[]int{3}}, Value:"github.com/tj/something/here"}
+	// makefile.Include{node:makefile.node{pos:makefile.Pos{File:"", Line:2, Column:1}, lines:[]int{1}}, Value:"github.com/tj/foo", Optional:false}
+	// makefile.Include{node:makefile.node{pos:makefile.Pos{File:"", Line:3, Column:1}, lines:[]int{2}}, Value:"github.com/tj/bar", Optional:false}
+	// makefile.Include{node:makefile.node{pos:makefile.Pos{File:"", Line:4, Column:1}, lines:[]int{3}}, Value:"github.com/tj/something/here", Optional:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:5, Column:1}, lines:[]int{4}}, Targets:[]string{"start"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:6, Column:2}, lines:[]int{5}}, Value:"@gopherjs -m -v serve --http :3000 github.com/tj/docs/client", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:7, Column:1}, lines:[]int{6}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"start"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:8, Column:1}, lines:[]int{7}}, Targets:[]string{"api"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:9, Column:2}, lines:[]int{8}}, Value:"@go run server/cmd/api/api.go", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:10, Column:1}, lines:[]int{9}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"api"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:11, Column:1}, lines:[]int{10}}, Targets:[]string{"deps"}, DoubleColon:false, Prereqs:[]string(nil), OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:12, Column:2}, lines:[]int{11}}, Value:"@godepgraph github.com/tj/docs/client | dot -Tsvg | browser", Refs:[]string(nil)}}, Refs:[]string(nil)}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:13, Column:1}, lines:[]int{12}}, Targets:[]string{".PHONY"}, DoubleColon:false, Prereqs:[]string{"deps"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe(nil), Refs:[]string(nil)}
+}
+
+func ExampleParser_Parse_withVariables() {
+	contents := `GO ?= go
+export FILES := $(shell find . -name '*.go' | grep -v $(VENDOR))
+include .bingo/Variables.mk
+lint: $(GOLANGCI_LINT)
+	@$(GOLANGCI_LINT) run $(FILES:.go=) ; echo $$HOME
+`
+
+	nodes, err := Parse(strings.NewReader(contents))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, node := range nodes {
+		fmt.Printf("%#v\n", node)
+	}
+
+	// Output:
+	// makefile.Assignment{node:makefile.node{pos:makefile.Pos{File:"", Line:1, Column:1}, lines:[]int{0}}, Name:"GO", Op:"?=", Value:"go", Export:false, Override:false, Targets:[]string(nil), Refs:[]string(nil)}
+	// makefile.Assignment{node:makefile.node{pos:makefile.Pos{File:"", Line:2, Column:1}, lines:[]int{1}}, Name:"FILES", Op:":=", Value:"$(shell find . -name '*.go' | grep -v $(VENDOR))", Export:true, Override:false, Targets:[]string(nil), Refs:[]string{"VENDOR"}}
+	// makefile.Include{node:makefile.node{pos:makefile.Pos{File:"", Line:3, Column:1}, lines:[]int{2}}, Value:".bingo/Variables.mk", Optional:false}
+	// makefile.Rule{node:makefile.node{pos:makefile.Pos{File:"", Line:4, Column:1}, lines:[]int{3}}, Targets:[]string{"lint"}, DoubleColon:false, Prereqs:[]string{"$(GOLANGCI_LINT)"}, OrderOnly:[]string(nil), Recipe:[]makefile.Recipe{makefile.Recipe{node:makefile.node{pos:makefile.Pos{File:"", Line:5, Column:2}, lines:[]int{4}}, Value:"@$(GOLANGCI_LINT) run $(FILES:.go=) ; echo $$HOME", Refs:[]string{"GOLANGCI_LINT", "FILES"}}}, Refs:[]string{"GOLANGCI_LINT"}}
+}
+
+// dump renders nodes in a compact form, one per line, with nested nodes indented.
+func dump(nodes []Node, indent string) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(indent + n.Pos().String() + " ")
+		switch n := n.(type) {
+		case Comment:
+			fmt.Fprintf(&b, "Comment target=%q inline=%v %q", n.Target, n.Inline, n.Value)
+		case Include:
+			fmt.Fprintf(&b, "Include optional=%v %q", n.Optional, n.Files())
+		case Assignment:
+			fmt.Fprintf(&b, "Assignment export=%v override=%v targets=%q %v %v %q refs=%q", n.Export, n.Override, n.Targets, n.Name, n.Op, n.Value, n.Refs)
+		case Rule:
+			fmt.Fprintf(&b, "Rule targets=%q double=%v prereqs=%q order=%q refs=%q", n.Targets, n.DoubleColon, n.Prereqs, n.OrderOnly, n.Refs)
+			for _, r := range n.Recipe {
+				fmt.Fprintf(&b, "\n%v  %v Recipe %q refs=%q", indent, r.Pos(), r.Value, r.Refs)
+			}
+		case Conditional:
+			fmt.Fprintf(&b, "Conditional %v %q refs=%q\n", n.Directive, n.Condition, n.Refs)
+			b.WriteString(dump(n.Then, indent+"  then "))
+			b.WriteString(dump(n.Else, indent+"  else "))
+			continue
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestParser_Next(t *testing.T) {
+	contents := `# Tools.
+-include .bingo/Variables.mk
+override export GOFLAGS = -mod=mod \
+	-trimpath # Comment.
+
+ifeq ($(OS),Windows_NT)
+    EXT := .exe
+else ifdef CROSS
+    EXT := $(CROSS_EXT)
+else
+    EXT ?=
+endif
+
+define HELP
+Usage:
+	make $(TARGET)
+endef
+
+# Build all.
+all:: build $(TOOLS) | out ; @echo "done"
+
+build: GOFLAGS += -v
+# Build with linting.
+build: $(FAILLINT) ## Build.
+	@$(FAILLINT) -paths fmt.Println \
+		./...
+
+	go build ./...
+`
+
+	p := NewParser(strings.NewReader(contents), "Makefile")
+	var nodes []Node
+	for {
+		n, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		testutil.Ok(t, err)
+		nodes = append(nodes, n)
+	}
+	testutil.Equals(t, `Makefile:1:1 Comment target="" inline=false "Tools."
+Makefile:2:1 Include optional=true [".bingo/Variables.mk"]
+Makefile:3:1 Assignment export=true override=true targets=[] GOFLAGS = "-mod=mod -trimpath" refs=[]
+Makefile:6:1 Conditional ifeq "($(OS),Windows_NT)" refs=["OS"]
+  then Makefile:7:5 Assignment export=false override=false targets=[] EXT := ".exe" refs=[]
+  else Makefile:8:1 Conditional ifdef "CROSS" refs=[]
+  else   then Makefile:9:5 Assignment export=false override=false targets=[] EXT := "$(CROSS_EXT)" refs=["CROSS_EXT"]
+  else   else Makefile:11:5 Assignment export=false override=false targets=[] EXT ?= "" refs=[]
+Makefile:14:1 Assignment export=false override=false targets=[] HELP = "Usage:\n\tmake $(TARGET)" refs=["TARGET"]
+Makefile:19:1 Comment target="all" inline=false "Build all."
+Makefile:20:1 Rule targets=["all"] double=true prereqs=["build" "$(TOOLS)"] order=["out"] refs=["TOOLS"]
+  Makefile:20:1 Recipe "@echo \"done\"" refs=[]
+Makefile:22:1 Assignment export=false override=false targets=["build"] GOFLAGS += "-v" refs=[]
+Makefile:23:1 Comment target="build" inline=false "Build with linting."
+Makefile:24:1 Comment target="build" inline=true "Build."
+Makefile:24:1 Rule targets=["build"] double=false prereqs=["$(FAILLINT)"] order=[] refs=["FAILLINT"]
+  Makefile:25:2 Recipe "@$(FAILLINT) -paths fmt.Println \\\n\t./..." refs=["FAILLINT"]
+  Makefile:28:2 Recipe "go build ./..." refs=[]
+`, dump(nodes, ""))
+}
+
+func TestParser_ConditionalRecipe(t *testing.T) {
+	for _, tcase := range []struct {
+		contents string
+		expected string
+	}{
+		{
+			// Conditionals within the recipe do not end the rule.
+			contents: "build:\nifeq ($(X),1)\n\t$(GOLANGCI_LINT) run\nelse\n\tdocker run -v $(PWD):/src img\nendif\n\techo done\n",
+			expected: `1:1 Rule targets=["build"] double=false prereqs=[] order=[] refs=[]
+  3:2 Recipe "$(GOLANGCI_LINT) run" refs=["GOLANGCI_LINT"]
+  5:2 Recipe "docker run -v $(PWD):/src img" refs=["PWD"]
+  7:2 Recipe "echo done" refs=[]
+2:1 Conditional ifeq "($(X),1)" refs=["X"]
+`,
+		},
+		{
+			contents: "ifdef CI\nlint:\n\tgolangci-lint run\nelse\nX := 1\nlint:\n\techo skip\nendif\n\techo orphan\n",
+			expected: `1:1 Conditional ifdef "CI" refs=[]
+  then 2:1 Rule targets=["lint"] double=false prereqs=[] order=[] refs=[]
+  then   3:2 Recipe "golangci-lint run" refs=[]
+  else 5:1 Assignment export=false override=false targets=[] X := "1" refs=[]
+  else 6:1 Rule targets=["lint"] double=false prereqs=[] order=[] refs=[]
+  else   7:2 Recipe "echo skip" refs=[]
+`,
+		},
+		{
+			contents: "build:\n\techo start\nifdef CI\nX := 1\n\techo ci\nendif\n",
+			expected: `1:1 Rule targets=["build"] double=false prereqs=[] order=[] refs=[]
+  2:2 Recipe "echo start" refs=[]
+3:1 Conditional ifdef "CI" refs=[]
+  then 4:1 Assignment export=false override=false targets=[] X := "1" refs=[]
+`,
+		},
+	} {
+		t.Run(tcase.contents, func(t *testing.T) {
+			nodes, err := Parse(strings.NewReader(tcase.contents))
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, dump(nodes, ""))
+		})
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	for _, tcase := range []struct {
+		contents string
+		expected string
+	}{
+		{contents: "ifdef A\nB := 1\n", expected: "parsing: 1:1: missing endif"},
+		{contents: "B := 1\nendif\n", expected: "parsing: 2:1: endif without if"},
+		{contents: "ifdef A\nelse\nelse\nendif\n", expected: "parsing: 3:1: only one else per conditional"},
+		{contents: "define A\nB := 1\n", expected: "parsing: 1:1: missing endef"},
+	} {
+		_, err := Parse(strings.NewReader(tcase.contents))
+		testutil.NotOk(t, err)
+		testutil.Equals(t, tcase.expected, err.Error())
+	}
+}
+
+func TestParseFileRecursive(t *testing.T) {
+	dir := t.TempDir()
+	for f, c := range map[string]string{
+		"Makefile":             "# Default.\nall:\n\ninclude a.mk $(MISSING).mk\nifdef CI\n-include missing.mk b.mk\nendif\n",
+		"a.mk":                 "A := 1",
+		"b.mk":                 "include .bingo/Variables.mk\n",
+		".bingo/Variables.mk":  "GO ?= go\n",
+		"cycle/Makefile":       "include a.mk\n",
+		"cycle/a.mk":           "include Makefile\n",
+		"missing/Makefile":     "include missing.mk\n",
+		"missing/.bingo/x.mod": "",
+	} {
+		testutil.Ok(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), os.ModePerm))
+		testutil.Ok(t, os.WriteFile(filepath.Join(dir, f), []byte(c), os.ModePerm))
+	}
+	t.Chdir(dir)
+
+	nodes, err := ParseFileRecursive("Makefile")
+	testutil.Ok(t, err)
+	testutil.Equals(t, `Makefile:1:1 Comment target="all" inline=false "Default."
+Makefile:2:1 Rule targets=["all"] double=false prereqs=[] order=[] refs=[]
+Makefile:4:1 Include optional=false ["a.mk" "$(MISSING).mk"]
+a.mk:1:1 Assignment export=false override=false targets=[] A := "1" refs=[]
+Makefile:5:1 Conditional ifdef "CI" refs=[]
+  then Makefile:6:1 Include optional=true ["missing.mk" "b.mk"]
+  then b.mk:1:1 Include optional=false [".bingo/Variables.mk"]
+  then .bingo/Variables.mk:1:1 Assignment export=false override=false targets=[] GO ?= "go" refs=[]
+`, dump(nodes, ""))
+	testutil.Equals(t, true, nodes[0].(Comment).Default)
+
+	var assignments []string
+	Walk(nodes, func(n Node) bool {
+		if a, ok := n.(Assignment); ok {
+			assignments = append(assignments, a.Name)
+		}
+		return true
+	})
+	testutil.Equals(t, []string{"A", "GO"}, assignments)
+
+	_, err = ParseFileRecursive("cycle/Makefile")
+	testutil.NotOk(t, err)
+	testutil.Equals(t, `cycle/a.mk:1:1: include cycle: cycle/Makefile -> cycle/a.mk -> cycle/Makefile`, err.Error())
+
+	_, err = ParseFileRecursive("missing/Makefile")
+	testutil.NotOk(t, err)
 }
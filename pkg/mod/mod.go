@@ -125,6 +125,30 @@ func (mf *File) AddComment(comment string) error {
 	return mf.flush()
 }
 
+// DropComments removes all standalone and statement comments for which the given function returns true.
+// Comment is passed to the function without the '// ' prefix, the same as returned by Comments.
+func (mf *File) DropComments(drop func(comment string) bool) error {
+	stmts := mf.m.Syntax.Stmt[:0]
+	for _, e := range mf.m.Syntax.Stmt {
+		c := e.Comment()
+		before := c.Before[:0]
+		for _, b := range c.Before {
+			if drop(b.Token[3:]) {
+				continue
+			}
+			before = append(before, b)
+		}
+		c.Before = before
+
+		if cb, ok := e.(*modfile.CommentBlock); ok && len(cb.Before) == 0 && len(cb.Suffix) == 0 && len(cb.After) == 0 {
+			continue
+		}
+		stmts = append(stmts, e)
+	}
+	mf.m.Syntax.Stmt = stmts
+	return mf.flush()
+}
+
 // GoVersion returns a semver string containing the value of of the go directive.
 // For example, it will return "1.2.3" if the go.mod file contains the line "go 1.2.3".
 // If no go directive is found, it returns "1.0" because:
@@ -0,0 +1,62 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package mod
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/efficientgo/core/errors"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// SumFilePath returns path of the sum file Go maintains for the given module file, e.g. faillint.sum for faillint.mod.
+func SumFilePath(modFile string) string {
+	return strings.TrimSuffix(modFile, ".mod") + ".sum"
+}
+
+// ReadSumFile returns modules which content (not only go.mod file) is recorded in the given sum file, so modules that
+// were downloaded to build packages. If there are many versions of the same module, only the highest one is returned.
+// Modules are returned in the order of the sum file. Not existing file is treated as empty one.
+func ReadSumFile(sumFile string) ([]module.Version, error) {
+	b, err := os.ReadFile(sumFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var (
+		ret   []module.Version
+		index = map[string]int{}
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for i := 1; scanner.Scan(); i++ {
+		f := strings.Fields(scanner.Text())
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) != 3 {
+			return nil, errors.Newf("%v:%d: malformed line; expected '<module> <version> <hash>'", sumFile, i)
+		}
+		if strings.HasSuffix(f[1], "/go.mod") {
+			continue
+		}
+
+		m := module.Version{Path: f[0], Version: f[1]}
+		if j, ok := index[m.Path]; ok {
+			if semver.Compare(m.Version, ret[j].Version) > 0 {
+				ret[j] = m
+			}
+			continue
+		}
+		index[m.Path] = len(ret)
+		ret = append(ret, m)
+	}
+	return ret, scanner.Err()
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+	"golang.org/x/mod/module"
+)
+
+func TestReadSumFile(t *testing.T) {
+	dir := t.TempDir()
+	f := SumFilePath(filepath.Join(dir, "faillint.mod"))
+	testutil.Equals(t, filepath.Join(dir, "faillint.sum"), f)
+
+	mods, err := ReadSumFile(f)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(mods))
+
+	testutil.Ok(t, os.WriteFile(f, []byte(`github.com/fatih/faillint v1.5.0 h1:oqYG8sXgH4qTuVDGPvUvZTYd3JOqIt6IM1mHMy8H/Bk=
+github.com/fatih/faillint v1.5.0/go.mod h1:Yu1dbkfJiaU+aDa+X1Bk0TOwpC3zq+o2WSrgq9XpcQM=
+golang.org/x/tools v0.0.0-20200207224406-61798d64f025/go.mod h1:TB2adYChydJhpapKDTa4BR/hXlZSLoq2Wpct/0txZ28=
+golang.org/x/tools v0.1.0 h1:po9/4sTYwZU9lPhi1usS6DYbNfL9DSOQA3HSF1DNKnw=
+golang.org/x/tools v0.0.9 h1:aaaa=
+
+golang.org/x/mod v0.3.0/go.mod h1:s0Qsj1ACt9ePp/hMypM3fl4fZqREWJwdYZPWW8x2DuY=
+`), os.ModePerm))
+
+	mods, err = ReadSumFile(f)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []module.Version{
+		{Path: "github.com/fatih/faillint", Version: "v1.5.0"},
+		{Path: "golang.org/x/tools", Version: "v0.1.0"},
+	}, mods)
+
+	testutil.Ok(t, os.WriteFile(f, []byte("golang.org/x/tools v0.1.0\n"), os.ModePerm))
+	_, err = ReadSumFile(f)
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package osv
+
+import (
+	"math"
+	"strings"
+
+	"github.com/efficientgo/core/errors"
+)
+
+// Severity is a qualitative severity rating of the vulnerability, as defined by CVSS v3.
+type Severity int
+
+const (
+	// SeverityUnknown is used when the database does not rate the vulnerability (e.g. Go vulnerability database).
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = []string{"unknown", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return severityNames[SeverityUnknown]
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses severity rating (e.g. "high" or "HIGH"). GitHub's "moderate" is accepted as medium.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(s)
+	if s == "moderate" {
+		return SeverityMedium, nil
+	}
+	for i, n := range severityNames {
+		if s == n {
+			return Severity(i), nil
+		}
+	}
+	return SeverityUnknown, errors.Newf("unknown severity %q; expected one of low, medium, high or critical", s)
+}
+
+// severityFromScore returns rating of the CVSS v3 base score.
+func severityFromScore(score float64) Severity {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+	return SeverityUnknown
+}
+
+var cvssWeights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"S":  {"U": 0, "C": 0},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSSv3BaseScore computes the base score from the CVSS v3.0 or v3.1 vector
+// (e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"). Temporal and environmental metrics are ignored.
+func CVSSv3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return 0, errors.Newf("not a CVSS v3 vector %q", vector)
+	}
+
+	m := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, ok := strings.Cut(p, ":")
+		if !ok {
+			return 0, errors.Newf("malformed metric %q in CVSS vector %q", p, vector)
+		}
+		m[k] = v
+	}
+	w := map[string]float64{}
+	for k, weights := range cvssWeights {
+		v, ok := weights[m[k]]
+		if !ok {
+			return 0, errors.Newf("missing or invalid base metric %v in CVSS vector %q", k, vector)
+		}
+		w[k] = v
+	}
+
+	changed := m["S"] == "C"
+	if changed {
+		// Privileges required matter more, when scope is changed.
+		switch m["PR"] {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp returns the smallest number, with one decimal place, equal to or higher than x, as defined by CVSS v3.1.
+func roundUp(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
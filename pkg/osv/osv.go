@@ -0,0 +1,403 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package osv allows matching Go module versions against vulnerability database in OSV format
+// (https://ossf.github.io/osv-schema), stored on disk (e.g. https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip),
+// so it can be used offline.
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/efficientgo/core/errcapture"
+	"github.com/efficientgo/core/errors"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GoEcosystem is the OSV ecosystem of Go modules.
+const GoEcosystem = "Go"
+
+// Entry is a subset of the OSV vulnerability entry, used for matching Go modules.
+type Entry struct {
+	ID         string     `json:"id"`
+	Summary    string     `json:"summary"`
+	Aliases    []string   `json:"aliases"`
+	Withdrawn  string     `json:"withdrawn"`
+	Affected   []Affected `json:"affected"`
+	Severities []Score    `json:"severity"`
+
+	DatabaseSpecific struct {
+		// Severity is a rating of the vulnerability as given by the database, e.g. "HIGH" in GitHub advisories.
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges     []Range  `json:"ranges"`
+	Versions   []string `json:"versions"`
+	Severities []Score  `json:"severity"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+type Score struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Severity returns the highest severity of the entry, computed from its CVSS v3 vectors or, if there are none, taken
+// from the database specific rating. It returns SeverityUnknown if the entry is not rated.
+func (e *Entry) Severity() Severity {
+	var (
+		ret    = SeverityUnknown
+		scores = e.Severities
+	)
+	for _, a := range e.Affected {
+		scores = append(scores[:len(scores):len(scores)], a.Severities...)
+	}
+	for _, s := range scores {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		score, err := CVSSv3BaseScore(s.Score)
+		if err != nil {
+			continue
+		}
+		if sev := severityFromScore(score); sev > ret {
+			ret = sev
+		}
+	}
+	if ret != SeverityUnknown {
+		return ret
+	}
+	ret, _ = ParseSeverity(e.DatabaseSpecific.Severity)
+	return ret
+}
+
+// canonical returns canonical semver for OSV version, which is given without "v" prefix for Go ecosystem. It returns
+// empty string for invalid version.
+func canonical(v string) string {
+	if v == "" {
+		return ""
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return semver.Canonical(v)
+}
+
+// affects returns true if the given canonical version is affected by the range. If it is, it also returns the lowest
+// version fixing it, if any.
+func (r Range) affects(v string) (affected bool, fixed string) {
+	if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+		return false, ""
+	}
+
+	type event struct {
+		version string
+		kind    int
+	}
+	const (
+		introduced = iota
+		fixedKind
+		lastAffected
+	)
+	events := make([]event, 0, len(r.Events))
+	for _, e := range r.Events {
+		switch {
+		case e.Introduced == "0":
+			// The lowest possible version, which is not valid semver.
+			events = append(events, event{kind: introduced})
+		case e.Introduced != "":
+			events = append(events, event{version: canonical(e.Introduced), kind: introduced})
+		case e.Fixed != "":
+			events = append(events, event{version: canonical(e.Fixed), kind: fixedKind})
+		case e.LastAffected != "":
+			events = append(events, event{version: canonical(e.LastAffected), kind: lastAffected})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].version == "" || events[j].version == "" {
+			return events[i].version == "" && events[j].version != ""
+		}
+		return semver.Compare(events[i].version, events[j].version) < 0
+	})
+
+	for _, e := range events {
+		if e.version != "" && semver.Compare(v, e.version) < 0 {
+			if affected && e.kind == fixedKind {
+				return true, e.version
+			}
+			break
+		}
+		switch e.kind {
+		case introduced:
+			affected = true
+		case fixedKind:
+			affected = false
+		case lastAffected:
+			// Equal version is still affected.
+			affected = semver.Compare(v, e.version) <= 0 && affected
+		}
+	}
+	return affected, ""
+}
+
+// Finding is a vulnerability affecting the queried module version. Entries describing the same vulnerability (e.g.
+// GO-2099-0001 and its GHSA alias) are reported as a single finding.
+type Finding struct {
+	ID      string
+	Aliases []string
+	Summary string
+	// Severity is the highest severity of the entry and the entries it's aliased with, so vulnerabilities from Go
+	// vulnerability database (which does not rate them) are rated by their GitHub advisories, if loaded.
+	Severity Severity
+	// Fixed is the lowest version fixing the vulnerability, higher than the affected one. Empty if there is no fix.
+	Fixed string
+}
+
+// DB is an in-memory vulnerability database indexed by Go module path.
+type DB struct {
+	entries  int
+	byModule map[string][]*Entry
+	// byAlias maps IDs and aliases to entries having them as ID or alias.
+	byAlias map[string][]*Entry
+}
+
+// Len returns number of entries affecting Go modules.
+func (db *DB) Len() int {
+	return db.entries
+}
+
+// Add adds entry to the database. Withdrawn entries and entries not affecting Go modules are ignored.
+func (db *DB) Add(e *Entry) {
+	if e.Withdrawn != "" {
+		return
+	}
+	if db.byModule == nil {
+		db.byModule = map[string][]*Entry{}
+		db.byAlias = map[string][]*Entry{}
+	}
+	for _, id := range e.ids() {
+		db.byAlias[id] = append(db.byAlias[id], e)
+	}
+
+	added := map[string]struct{}{}
+	for _, a := range e.Affected {
+		if a.Package.Ecosystem != GoEcosystem {
+			continue
+		}
+		if _, ok := added[a.Package.Name]; ok {
+			continue
+		}
+		added[a.Package.Name] = struct{}{}
+		db.byModule[a.Package.Name] = append(db.byModule[a.Package.Name], e)
+	}
+	if len(added) > 0 {
+		db.entries++
+	}
+}
+
+// Query returns vulnerabilities affecting the given module version, sorted by ID.
+func (db *DB) Query(m module.Version) []Finding {
+	v := semver.Canonical(m.Version)
+	if v == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, e := range db.byModule[m.Path] {
+		affected, fixed := false, ""
+		for _, a := range e.Affected {
+			if a.Package.Ecosystem != GoEcosystem || a.Package.Name != m.Path {
+				continue
+			}
+			for _, av := range a.Versions {
+				if canonical(av) == v {
+					affected = true
+				}
+			}
+			for _, r := range a.Ranges {
+				ok, f := r.affects(v)
+				if !ok {
+					continue
+				}
+				affected = true
+				if f != "" && (fixed == "" || semver.Compare(f, fixed) < 0) {
+					fixed = f
+				}
+			}
+		}
+		if !affected {
+			continue
+		}
+		findings = append(findings, Finding{
+			ID:       e.ID,
+			Aliases:  e.Aliases,
+			Summary:  e.Summary,
+			Severity: db.severity(e),
+			Fixed:    fixed,
+		})
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].ID < findings[j].ID })
+	return dedupAliased(findings)
+}
+
+// ids returns ID and aliases of the entry.
+func (e *Entry) ids() []string {
+	return append([]string{e.ID}, e.Aliases...)
+}
+
+// severity returns severity of the entry or, if it's not rated, the highest severity of entries it's aliased with.
+func (db *DB) severity(e *Entry) Severity {
+	ret := e.Severity()
+	if ret != SeverityUnknown {
+		return ret
+	}
+	for _, id := range e.ids() {
+		for _, a := range db.byAlias[id] {
+			if s := a.Severity(); s > ret {
+				ret = s
+			}
+		}
+	}
+	return ret
+}
+
+// dedupAliased merges findings sharing ID or any alias into the first of them (findings are sorted by ID). Merged finding
+// has all aliases, the highest severity and the highest version fixing the vulnerability, if any.
+func dedupAliased(findings []Finding) []Finding {
+	var ret []Finding
+FindingLoop:
+	for _, f := range findings {
+		for i := range ret {
+			if !aliased(ret[i], f) {
+				continue
+			}
+			for _, id := range append([]string{f.ID}, f.Aliases...) {
+				if id != ret[i].ID && !slices.Contains(ret[i].Aliases, id) {
+					ret[i].Aliases = append(ret[i].Aliases, id)
+				}
+			}
+			sort.Strings(ret[i].Aliases)
+			ret[i].Severity = max(ret[i].Severity, f.Severity)
+			if ret[i].Fixed == "" || (f.Fixed != "" && semver.Compare(f.Fixed, ret[i].Fixed) > 0) {
+				ret[i].Fixed = f.Fixed
+			}
+			continue FindingLoop
+		}
+		f.Aliases = slices.Clone(f.Aliases)
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+func aliased(a, b Finding) bool {
+	ids := append([]string{a.ID}, a.Aliases...)
+	for _, id := range append([]string{b.ID}, b.Aliases...) {
+		if slices.Contains(ids, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load loads OSV entries from the given path, which can be a directory (JSON files are read recursively), a zip
+// archive of JSON files (e.g. all.zip exported by osv.dev) or a single JSON file.
+func Load(path string) (*DB, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{}
+	if fi.IsDir() {
+		if err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(p) != ".json" {
+				return nil
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return db.addJSON(p, b)
+		}); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	if filepath.Ext(path) == ".zip" {
+		return db, db.addZip(path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return db, db.addJSON(path, b)
+}
+
+func (db *DB) addZip(path string) (err error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return errors.Wrapf(err, "open %v", path)
+	}
+	defer errcapture.Do(&err, r.Close, "close")
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || filepath.Ext(f.Name) != ".json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return errors.Wrapf(err, "open %v in %v", f.Name, path)
+		}
+		b, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, "read %v in %v", f.Name, path)
+		}
+		if err := db.addJSON(path+":"+f.Name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) addJSON(name string, b []byte) error {
+	e := &Entry{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return errors.Wrapf(err, "parse OSV entry %v", name)
+	}
+	if e.ID == "" {
+		return errors.Newf("parse OSV entry %v: missing id", name)
+	}
+	db.Add(e)
+	return nil
+}
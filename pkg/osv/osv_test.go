@@ -0,0 +1,150 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package osv
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/core/testutil"
+	"golang.org/x/mod/module"
+)
+
+func TestCVSSv3BaseScore(t *testing.T) {
+	for vector, expected := range map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H": 10,
+		"CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H": 5.9,
+		"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N": 6.4,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:L/I:N/A:N": 4.3,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	} {
+		t.Run(vector, func(t *testing.T) {
+			score, err := CVSSv3BaseScore(vector)
+			testutil.Ok(t, err)
+			testutil.Equals(t, expected, score)
+		})
+	}
+
+	for _, vector := range []string{"", "CVSS:2.0/AV:N", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", "CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"} {
+		_, err := CVSSv3BaseScore(vector)
+		testutil.NotOk(t, err, vector)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("HIGH")
+	testutil.Ok(t, err)
+	testutil.Equals(t, SeverityHigh, s)
+	testutil.Equals(t, "high", s.String())
+
+	s, err = ParseSeverity("moderate")
+	testutil.Ok(t, err)
+	testutil.Equals(t, SeverityMedium, s)
+
+	_, err = ParseSeverity("severe")
+	testutil.NotOk(t, err)
+}
+
+func testDB(t *testing.T, db *DB) {
+	t.Helper()
+
+	// Withdrawn entry and entries of other ecosystems are ignored.
+	testutil.Equals(t, 5, db.Len())
+
+	for _, tcase := range []struct {
+		m        module.Version
+		expected []Finding
+	}{
+		{m: module.Version{Path: "example.com/other", Version: "v1.0.0"}},
+		{m: module.Version{Path: "example.com/parser", Version: "v1.2.0"}},
+		{m: module.Version{Path: "example.com/parser", Version: "v1.4.0"}},
+		{
+			// GitHub advisory aliased with the Go one is the same vulnerability.
+			m: module.Version{Path: "example.com/parser", Version: "v1.1.9"},
+			expected: []Finding{{
+				ID: "GHSA-gggg-hhhh-iiii", Aliases: []string{"CVE-2099-0001", "GO-2099-0001"}, Summary: "example.com/parser vulnerable to infinite loop",
+				Severity: SeverityHigh, Fixed: "v1.2.0",
+			}},
+		},
+		{
+			m: module.Version{Path: "example.com/parser", Version: "v1.3.4-0.20990101000000-abcdefabcdef"},
+			expected: []Finding{{
+				ID: "GO-2099-0001", Aliases: []string{"CVE-2099-0001"}, Summary: "Infinite loop when parsing malformed input in example.com/parser",
+				Severity: SeverityHigh, Fixed: "v1.3.4",
+			}},
+		},
+		{
+			m: module.Version{Path: "example.com/parser", Version: "v1.3.3"},
+			expected: []Finding{{
+				ID: "GO-2099-0001", Aliases: []string{"CVE-2099-0001"}, Summary: "Infinite loop when parsing malformed input in example.com/parser",
+				Severity: SeverityHigh, Fixed: "v1.3.4",
+			}},
+		},
+		{
+			m: module.Version{Path: "example.com/tool", Version: "v1.5.1"},
+			expected: []Finding{
+				{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2099-0003"}, Summary: "Remote code execution in example.com/tool", Severity: SeverityCritical, Fixed: "v1.9.1"},
+				{ID: "GHSA-dddd-eeee-ffff", Summary: "Denial of service in example.com/tool", Severity: SeverityMedium},
+			},
+		},
+		{
+			m: module.Version{Path: "example.com/tool", Version: "v2.1.0"},
+			expected: []Finding{
+				{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"CVE-2099-0003"}, Summary: "Remote code execution in example.com/tool", Severity: SeverityCritical},
+			},
+		},
+		{m: module.Version{Path: "example.com/tool", Version: "v2.1.1"}},
+		{
+			// Not rated and not aliased.
+			m: module.Version{Path: "example.com/transitive", Version: "v0.1.0"},
+			expected: []Finding{{
+				ID: "GO-2099-0003", Summary: "Path traversal in example.com/transitive", Severity: SeverityUnknown,
+			}},
+		},
+	} {
+		t.Run(tcase.m.String(), func(t *testing.T) {
+			testutil.Equals(t, tcase.expected, db.Query(tcase.m))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		db, err := Load("testdata/db")
+		testutil.Ok(t, err)
+		testDB(t, db)
+	})
+	t.Run("zip", func(t *testing.T) {
+		zipFile := filepath.Join(t.TempDir(), "all.zip")
+		f, err := os.Create(zipFile)
+		testutil.Ok(t, err)
+
+		w := zip.NewWriter(f)
+		for _, entry := range []string{"GO-2099-0001.json", "GO-2099-0002.json", "GO-2099-0003.json", "ghsa/GHSA-aaaa-bbbb-cccc.json", "ghsa/GHSA-dddd-eeee-ffff.json", "ghsa/GHSA-gggg-hhhh-iiii.json"} {
+			b, err := os.ReadFile(filepath.Join("testdata/db", entry))
+			testutil.Ok(t, err)
+			zf, err := w.Create(filepath.Base(entry))
+			testutil.Ok(t, err)
+			_, err = zf.Write(b)
+			testutil.Ok(t, err)
+		}
+		testutil.Ok(t, w.Close())
+		testutil.Ok(t, f.Close())
+
+		db, err := Load(zipFile)
+		testutil.Ok(t, err)
+		testDB(t, db)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		f := filepath.Join(t.TempDir(), "GO-2099-0003.json")
+		testutil.Ok(t, os.WriteFile(f, []byte(`{"id": "GO-2099-0003", "affected": {}}`), os.ModePerm))
+
+		_, err := Load(f)
+		testutil.NotOk(t, err)
+	})
+}
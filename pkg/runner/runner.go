@@ -28,6 +28,7 @@ type Runner struct {
 
 	verbose   bool
 	goVersion *semver.Version
+	goEnv     envars.EnvSlice
 
 	logger *log.Logger
 }
@@ -81,6 +82,12 @@ func (r *Runner) Verbose() {
 	r.verbose = true
 }
 
+// SetGoEnv sets Go environment variables (e.g. GOPRIVATE=...) applied on top of the process environment for every
+// executed command. Per command environment variables still take precedence.
+func (r *Runner) SetGoEnv(e envars.EnvSlice) {
+	r.goEnv = e
+}
+
 var cmdsSupportingModFileArg = map[string]struct{}{
 	"init":    {},
 	"get":     {},
@@ -109,7 +116,12 @@ func (r *Runner) exec(ctx context.Context, output io.Writer, e envars.EnvSlice,
 	cmd := exec.CommandContext(ctx, command, args...)
 	cmd.Dir = filepath.Join(cmd.Dir, cd)
 	// TODO(bwplotka): Might be surprising, let's return err when this env variable is altered.
-	e = envars.MergeEnvSlices(os.Environ(), e...)
+	base := envars.MergeEnvSlices(os.Environ(), r.goEnv...)
+	if r.insecure {
+		// 'go get -insecure' is no longer supported, use environment equivalent instead.
+		base = envars.MergeEnvSlices(base, "GOINSECURE=*", "GONOSUMDB=*")
+	}
+	e = envars.MergeEnvSlices(base, e...)
 	e.Set("GO111MODULE=on")
 	e.Set("GOWORK=off")
 	cmd.Env = e
@@ -195,9 +207,6 @@ func (r *runnable) GoEnv(args ...string) (string, error) {
 // GetD runs 'go get -d' against separate go modules file with given arguments.
 func (r *runnable) GetD(packages ...string) (string, error) {
 	args := []string{"get", "-d"}
-	if r.r.insecure {
-		args = append(args, "-insecure")
-	}
 
 	out := &bytes.Buffer{}
 	if err := r.r.execGo(r.ctx, out, r.extraEnvVars, r.dir, r.modFile, append(args, packages...)...); err != nil {
@@ -0,0 +1,117 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/errors"
+)
+
+// pinnedBinaries returns versioned binary names (<name>-<version>) for all mod files of the given tool.
+func pinnedBinaries(name string, modFiles []string) ([]string, error) {
+	bins := make([]string, 0, len(modFiles))
+	for _, f := range modFiles {
+		pkg, err := bingo.ModDirectPackage(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "found unparsable mod file %v. Fix it manually or remove it", f)
+		}
+		bins = append(bins, name+"-"+pkg.Module.Version)
+	}
+	return bins, nil
+}
+
+// isLinkTo returns true if given path is a symlink to one of the given targets.
+func isLinkTo(path string, targets ...string) (bool, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return false, nil
+	}
+	dst, err := os.Readlink(path)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range targets {
+		if dst == t {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// remove unpins given tools by removing all their module files. If gobinDir is not empty, it also
+// removes all versioned binaries of those tools from gobinDir together with the <tool> link
+// created by `bingo get -l` (only if it points to one of removed binaries). Removed binaries are dropped from the
+// manifest file, if not empty.
+func remove(logger *log.Logger, modDir string, gobinDir, manifestFile string, verbose bool, names ...string) error {
+	for _, name := range names {
+		existing, err := existingModFiles(modDir, name)
+		if err != nil {
+			return errors.Wrapf(err, "existing mod files for %v", name)
+		}
+		if len(existing) == 0 {
+			return errors.Newf("nothing to remove, tool %v is not installed", name)
+		}
+
+		if gobinDir != "" {
+			bins, err := pinnedBinaries(name, existing)
+			if err != nil {
+				return err
+			}
+			binPaths := make([]string, 0, len(bins))
+			for _, b := range bins {
+				binPaths = append(binPaths, filepath.Join(gobinDir, b))
+			}
+
+			link := filepath.Join(gobinDir, name)
+			ok, err := isLinkTo(link, binPaths...)
+			if err != nil {
+				return errors.Wrapf(err, "check link %v", link)
+			}
+			if ok {
+				binPaths = append(binPaths, link)
+			}
+			for _, b := range binPaths {
+				if verbose {
+					logger.Println("removing", b)
+				}
+				if err := os.RemoveAll(b); err != nil {
+					return errors.Wrap(err, "rm")
+				}
+			}
+
+			if manifestFile != "" {
+				removed := map[string]struct{}{}
+				for _, b := range binPaths {
+					removed[b] = struct{}{}
+				}
+				if err := bingo.UpdateManifest(manifestFile, func(bins []bingo.BuiltBinary) ([]bingo.BuiltBinary, error) {
+					var kept []bingo.BuiltBinary
+					for _, b := range bins {
+						if _, ok := removed[b.Path]; !ok {
+							kept = append(kept, b)
+						}
+					}
+					return kept, nil
+				}); err != nil {
+					return errors.Wrap(err, "update manifest")
+				}
+			}
+		}
+
+		if err := removeAllGlob(filepath.Join(modDir, name+".*")); err != nil {
+			return errors.Wrapf(err, "remove mod files for %v", name)
+		}
+	}
+	return nil
+}
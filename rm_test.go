@@ -0,0 +1,156 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/core/testutil"
+)
+
+func writeTestModFile(t *testing.T, dir, file, pkg string) {
+	t.Helper()
+
+	testutil.Ok(t, os.WriteFile(filepath.Join(dir, file), []byte(fmt.Sprintf(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.24
+
+require %s
+`, pkg)), os.ModePerm))
+}
+
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := os.ReadDir(dir)
+	testutil.Ok(t, err)
+
+	ret := []string{}
+	for _, f := range files {
+		ret = append(ret, f.Name())
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestRemove(t *testing.T) {
+	logger := log.New(os.Stderr, "", 0)
+
+	setup := func(t *testing.T) (modDir, gobinDir string) {
+		modDir, gobinDir = filepath.Join(t.TempDir(), ".bingo"), t.TempDir()
+		testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+
+		writeTestModFile(t, modDir, "faillint.mod", "github.com/fatih/faillint v1.5.0")
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "faillint.sum"), nil, os.ModePerm))
+		writeTestModFile(t, modDir, "buildable.mod", "github.com/bwplotka/bingo-testmodule v1.0.0 // buildable")
+		writeTestModFile(t, modDir, "buildable.1.mod", "github.com/bwplotka/bingo-testmodule v1.1.0 // buildable")
+
+		for _, b := range []string{"faillint-v1.5.0", "buildable-v1.0.0", "buildable-v1.1.0", "faillint"} {
+			testutil.Ok(t, os.WriteFile(filepath.Join(gobinDir, b), nil, os.ModePerm))
+		}
+		testutil.Ok(t, os.Symlink(filepath.Join(gobinDir, "buildable-v1.1.0"), filepath.Join(gobinDir, "buildable")))
+		return modDir, gobinDir
+	}
+
+	t.Run("not installed", func(t *testing.T) {
+		modDir, _ := setup(t)
+		testutil.NotOk(t, remove(logger, modDir, "", "", false, "not-existing"))
+	})
+	t.Run("without purge", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		testutil.Ok(t, remove(logger, modDir, "", "", false, "faillint", "buildable"))
+
+		testutil.Equals(t, []string{}, dirFiles(t, modDir))
+		testutil.Equals(t, []string{"buildable", "buildable-v1.0.0", "buildable-v1.1.0", "faillint", "faillint-v1.5.0"}, dirFiles(t, gobinDir))
+	})
+	t.Run("with purge", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		manifestFile := filepath.Join(t.TempDir(), "binaries.jsonl")
+		for _, b := range []string{"faillint-v1.5.0", "buildable-v1.1.0", "goimports-v0.1.0"} {
+			testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{Path: filepath.Join(gobinDir, b)}))
+		}
+		testutil.Ok(t, remove(logger, modDir, gobinDir, manifestFile, false, "faillint", "buildable"))
+
+		testutil.Equals(t, []string{}, dirFiles(t, modDir))
+		// Non-link faillint binary was not created by bingo, so it's kept.
+		testutil.Equals(t, []string{"faillint"}, dirFiles(t, gobinDir))
+
+		bins, err := bingo.ReadManifest(manifestFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []bingo.BuiltBinary{{Path: filepath.Join(gobinDir, "goimports-v0.1.0")}}, bins)
+	})
+}
+
+func TestMove(t *testing.T) {
+	logger := log.New(os.Stderr, "", 0)
+
+	setup := func(t *testing.T) (modDir, gobinDir string) {
+		modDir, gobinDir = filepath.Join(t.TempDir(), ".bingo"), t.TempDir()
+		testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+
+		writeTestModFile(t, modDir, "faillint.mod", "github.com/fatih/faillint v1.5.0")
+		writeTestModFile(t, modDir, "buildable.mod", "github.com/bwplotka/bingo-testmodule v1.0.0 // buildable")
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "buildable.sum"), nil, os.ModePerm))
+		writeTestModFile(t, modDir, "buildable.1.mod", "github.com/bwplotka/bingo-testmodule v1.1.0 // buildable")
+
+		// buildable-v1.1.0 was never installed.
+		testutil.Ok(t, os.WriteFile(filepath.Join(gobinDir, "buildable-v1.0.0"), nil, os.ModePerm))
+		testutil.Ok(t, os.Symlink(filepath.Join(gobinDir, "buildable-v1.0.0"), filepath.Join(gobinDir, "buildable")))
+		return modDir, gobinDir
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		testutil.NotOk(t, move(logger, modDir, gobinDir, "", false, "not-existing", "new"))
+		testutil.NotOk(t, move(logger, modDir, gobinDir, "", false, "buildable", "buildable"))
+		testutil.NotOk(t, move(logger, modDir, gobinDir, "", false, "buildable", "faillint"))
+		testutil.NotOk(t, move(logger, modDir, gobinDir, "", false, "buildable", "cmd"))
+	})
+	t.Run("rename array tool", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		testutil.Ok(t, move(logger, modDir, gobinDir, "", false, "buildable", "my-buildable"))
+
+		testutil.Equals(t, []string{"faillint.mod", "my-buildable.1.mod", "my-buildable.mod", "my-buildable.sum"}, dirFiles(t, modDir))
+		testutil.Equals(t, []string{"my-buildable", "my-buildable-v1.0.0"}, dirFiles(t, gobinDir))
+
+		dst, err := os.Readlink(filepath.Join(gobinDir, "my-buildable"))
+		testutil.Ok(t, err)
+		testutil.Equals(t, filepath.Join(gobinDir, "my-buildable-v1.0.0"), dst)
+	})
+	t.Run("rename binsum", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		testutil.Ok(t, os.WriteFile(filepath.Join(modDir, "faillint.binsum"), nil, os.ModePerm))
+		testutil.Ok(t, move(logger, modDir, gobinDir, "", false, "faillint", "fl"))
+
+		// Recorded sha256 have to follow the tool, otherwise renamed tool would not be verified.
+		testutil.Equals(t, []string{"buildable.1.mod", "buildable.mod", "buildable.sum", "fl.binsum", "fl.mod"}, dirFiles(t, modDir))
+	})
+	t.Run("rename and gc", func(t *testing.T) {
+		modDir, gobinDir := setup(t)
+		manifestFile := filepath.Join(t.TempDir(), "binaries.jsonl")
+		builtAt := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+		testutil.Ok(t, bingo.RecordBuiltBinary(manifestFile, bingo.BuiltBinary{Path: filepath.Join(gobinDir, "buildable-v1.0.0"), Name: "buildable", Version: "v1.0.0", BuiltAt: builtAt}))
+		testutil.Ok(t, move(logger, modDir, gobinDir, manifestFile, false, "buildable", "my-buildable"))
+
+		bins, err := bingo.ReadManifest(manifestFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, []bingo.BuiltBinary{{Path: filepath.Join(gobinDir, "my-buildable-v1.0.0"), Name: "my-buildable", Version: "v1.0.0", BuiltAt: builtAt}}, bins)
+
+		// Renamed binary is still known to gc, so it's cleaned once not pinned anymore.
+		testutil.Ok(t, remove(logger, modDir, "", "", false, "my-buildable"))
+		pinned, err := pinnedBinarySet(logger, modDir)
+		testutil.Ok(t, err)
+		removed, err := gc(logger, gcConfig{gobinDir: gobinDir, manifestFile: manifestFile, pinned: pinned, now: builtAt})
+		testutil.Ok(t, err)
+		testutil.Equals(t, []string{filepath.Join(gobinDir, "my-buildable-v1.0.0")}, removed)
+		testutil.Equals(t, []string{}, dirFiles(t, gobinDir))
+	})
+}